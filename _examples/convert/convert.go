@@ -45,15 +45,19 @@ func convert(reader io.Reader, inputFormat PCMFormat, writer io.Writer, outputFo
 			return err
 		}
 
-		readFrameCount := n / inFrameSize
-		_, outFrameCount, err := converter.ProcessFrames(inBuffer, readFrameCount, outBuffer, expectFrames)
-		if err != nil {
-			return err
-		}
-
-		_, err = writer.Write(outBuffer[:outFrameCount*outFrameSize])
-		if err != nil {
-			return err
+		// Reads may end in the middle of a frame; ProcessBytes keeps the partial frame for the next call.
+		pending := inBuffer[:n]
+		for len(pending) > 0 {
+			consumed, outFrameCount, err := converter.ProcessBytes(pending, outBuffer, expectFrames)
+			if err != nil {
+				return err
+			}
+			pending = pending[consumed:]
+
+			_, err = writer.Write(outBuffer[:outFrameCount*outFrameSize])
+			if err != nil {
+				return err
+			}
 		}
 	}
 }
@@ -0,0 +1,116 @@
+package malgo
+
+import (
+	"math"
+)
+
+type adsrStage int
+
+const (
+	adsrIdle adsrStage = iota
+	adsrAttack
+	adsrDecay
+	adsrSustain
+	adsrRelease
+)
+
+// ADSR is an attack, decay, sustain, release amplitude envelope.
+//
+// All stages are linear ramps that start from the current level, so triggering or releasing a
+// note at any point never makes the gain jump. Only FormatF32 frames are supported.
+type ADSR struct {
+	attackStep   float64
+	decayStep    float64
+	sustainLevel float64
+	releaseTime  float64
+	sampleRate   float64
+	stage        adsrStage
+	level        float64
+	releaseStep  float64
+}
+
+// NewADSR creates an envelope. The attack, decay and release times are in seconds and the sustain
+// level is a linear gain between 0 and 1; values out of range are clamped.
+func NewADSR(sampleRate int, attack, decay, sustainLevel, release float64) *ADSR {
+	sustainLevel = clamp(sustainLevel, 0, 1)
+	return &ADSR{
+		attackStep:   rampStep(1, attack, float64(sampleRate)),
+		decayStep:    rampStep(1-sustainLevel, decay, float64(sampleRate)),
+		sustainLevel: sustainLevel,
+		releaseTime:  release,
+		sampleRate:   float64(sampleRate),
+	}
+}
+
+// rampStep returns the per frame change needed to cover distance in the given number of seconds.
+func rampStep(distance, seconds, sampleRate float64) float64 {
+	frames := seconds * sampleRate
+	if frames < 1 {
+		return math.Inf(1)
+	}
+	return distance / frames
+}
+
+// Trigger starts the attack stage from the current level.
+func (a *ADSR) Trigger() {
+	a.stage = adsrAttack
+}
+
+// Release starts the release stage from the current level. It has no effect once the envelope has finished.
+func (a *ADSR) Release() {
+	if a.stage == adsrIdle {
+		return
+	}
+	a.stage = adsrRelease
+	a.releaseStep = rampStep(a.level, a.releaseTime, a.sampleRate)
+}
+
+// Level returns the current envelope gain.
+func (a *ADSR) Level() float64 {
+	return a.level
+}
+
+// Active reports whether the envelope is running, that is it was triggered and has not finished its release.
+func (a *ADSR) Active() bool {
+	return a.stage != adsrIdle
+}
+
+// ProcessPCMFrames multiplies frameCount interleaved FormatF32 frames from in by the envelope and
+// writes them to out, advancing the envelope by one step per frame. The input and output buffers
+// may be the same slice.
+func (a *ADSR) ProcessPCMFrames(out, in []byte, frameCount, channels int) error {
+	sampleCount := frameCount * channels
+	if frameCount < 0 || channels <= 0 || len(in) < sampleCount*4 || len(out) < sampleCount*4 {
+		return ErrInvalidArgs
+	}
+
+	for frame := 0; frame < frameCount; frame++ {
+		a.advance()
+		for channel := 0; channel < channels; channel++ {
+			index := frame*channels + channel
+			setSampleAt(FormatF32, out, index, sampleAt(FormatF32, in, index)*a.level)
+		}
+	}
+
+	return nil
+}
+
+func (a *ADSR) advance() {
+	switch a.stage {
+	case adsrAttack:
+		a.level = math.Min(1, a.level+a.attackStep)
+		if a.level == 1 {
+			a.stage = adsrDecay
+		}
+	case adsrDecay:
+		a.level = math.Max(a.sustainLevel, a.level-a.decayStep)
+		if a.level == a.sustainLevel {
+			a.stage = adsrSustain
+		}
+	case adsrRelease:
+		a.level = math.Max(0, a.level-a.releaseStep)
+		if a.level == 0 {
+			a.stage = adsrIdle
+		}
+	}
+}
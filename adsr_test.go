@@ -0,0 +1,47 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestADSR(t *testing.T) {
+	// 10 frames of attack, 10 frames of decay to 0.5 and 20 frames of release at 1 kHz.
+	adsr := malgo.NewADSR(1000, 0.01, 0.01, 0.5, 0.02)
+
+	ones := make([]byte, 30*4)
+	for i := 0; i < 30; i++ {
+		binary.LittleEndian.PutUint32(ones[i*4:], math.Float32bits(1))
+	}
+	out := make([]byte, len(ones))
+	sample := func(i int) float64 {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(out[i*4:])))
+	}
+
+	err := adsr.ProcessPCMFrames(out, ones, 30, 1)
+	assertNil(t, err, "No error expected before trigger")
+	assertEqual(t, 0.0, sample(29), "Expected silence before trigger")
+
+	adsr.Trigger()
+	err = adsr.ProcessPCMFrames(out, ones, 30, 1)
+	assertNil(t, err, "No error expected processing frames")
+	assertTrue(t, math.Abs(sample(4)-0.5) < 1e-6, "Expected half level halfway through the attack")
+	assertTrue(t, math.Abs(sample(9)-1) < 1e-6, "Expected full level at the end of the attack")
+	assertTrue(t, math.Abs(sample(29)-0.5) < 1e-6, "Expected sustain level after the decay")
+
+	// Releasing during the attack ramps down from the level reached so far.
+	adsr = malgo.NewADSR(1000, 0.01, 0.01, 0.5, 0.02)
+	adsr.Trigger()
+	_ = adsr.ProcessPCMFrames(out, ones, 5, 1)
+	adsr.Release()
+	err = adsr.ProcessPCMFrames(out, ones, 30, 1)
+	assertNil(t, err, "No error expected releasing")
+	for i := 1; i < 30; i++ {
+		assertTrue(t, sample(i) <= sample(i-1) && sample(i-1)-sample(i) < 0.05, "Expected a smooth release")
+	}
+	assertEqual(t, 0.0, sample(29), "Expected silence after the release")
+	assertTrue(t, !adsr.Active(), "Expected envelope to finish")
+}
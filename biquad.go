@@ -0,0 +1,165 @@
+package malgo
+
+import (
+	"math"
+)
+
+// BiquadCoefficients type.
+//
+// The coefficients describe the transfer function (B0 + B1*z^-1 + B2*z^-2) / (A0 + A1*z^-1 + A2*z^-2),
+// normalized so that A0 is 1.
+type BiquadCoefficients struct {
+	B0, B1, B2 float64
+	A0, A1, A2 float64
+}
+
+func (c BiquadCoefficients) normalized() BiquadCoefficients {
+	return BiquadCoefficients{
+		B0: c.B0 / c.A0,
+		B1: c.B1 / c.A0,
+		B2: c.B2 / c.A0,
+		A0: 1,
+		A1: c.A1 / c.A0,
+		A2: c.A2 / c.A0,
+	}
+}
+
+// Magnitude returns the linear gain of the filter at the given frequency.
+func (c BiquadCoefficients) Magnitude(sampleRate, frequency float64) float64 {
+	w := 2 * math.Pi * frequency / sampleRate
+	z1 := complex(math.Cos(w), -math.Sin(w))
+	z2 := z1 * z1
+	num := complex(c.B0, 0) + complex(c.B1, 0)*z1 + complex(c.B2, 0)*z2
+	den := complex(c.A0, 0) + complex(c.A1, 0)*z1 + complex(c.A2, 0)*z2
+	return math.Hypot(real(num/den), imag(num/den))
+}
+
+// biquadFilter applies biquad coefficients to a single channel.
+type biquadFilter struct {
+	coefficients BiquadCoefficients
+	x1, x2       float64
+	y1, y2       float64
+}
+
+func (f *biquadFilter) process(x float64) float64 {
+	c := f.coefficients
+	y := c.B0*x + c.B1*f.x1 + c.B2*f.x2 - c.A1*f.y1 - c.A2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// The design functions below implement the formulas of the Audio EQ Cookbook, which are also used by
+// miniaudio's second order filters. Frequencies are in Hz.
+
+// DesignLPF designs a second order low-pass filter.
+func DesignLPF(sampleRate, cutoff, q float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, cutoff)
+	a := s / (2 * q)
+	return BiquadCoefficients{
+		B0: (1 - c) / 2,
+		B1: 1 - c,
+		B2: (1 - c) / 2,
+		A0: 1 + a,
+		A1: -2 * c,
+		A2: 1 - a,
+	}.normalized()
+}
+
+// DesignHPF designs a second order high-pass filter.
+func DesignHPF(sampleRate, cutoff, q float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, cutoff)
+	a := s / (2 * q)
+	return BiquadCoefficients{
+		B0: (1 + c) / 2,
+		B1: -(1 + c),
+		B2: (1 + c) / 2,
+		A0: 1 + a,
+		A1: -2 * c,
+		A2: 1 - a,
+	}.normalized()
+}
+
+// DesignBPF designs a second order band-pass filter with a peak gain of 0 dB.
+func DesignBPF(sampleRate, frequency, q float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, frequency)
+	a := s / (2 * q)
+	return BiquadCoefficients{
+		B0: a,
+		B1: 0,
+		B2: -a,
+		A0: 1 + a,
+		A1: -2 * c,
+		A2: 1 - a,
+	}.normalized()
+}
+
+// DesignNotch designs a second order notch filter.
+func DesignNotch(sampleRate, frequency, q float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, frequency)
+	a := s / (2 * q)
+	return BiquadCoefficients{
+		B0: 1,
+		B1: -2 * c,
+		B2: 1,
+		A0: 1 + a,
+		A1: -2 * c,
+		A2: 1 - a,
+	}.normalized()
+}
+
+// DesignPeak designs a second order peaking EQ filter.
+func DesignPeak(sampleRate, frequency, q, gainDB float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, frequency)
+	A := math.Pow(10, gainDB/40)
+	a := s / (2 * q)
+	return BiquadCoefficients{
+		B0: 1 + a*A,
+		B1: -2 * c,
+		B2: 1 - a*A,
+		A0: 1 + a/A,
+		A1: -2 * c,
+		A2: 1 - a/A,
+	}.normalized()
+}
+
+// DesignLowShelf designs a second order low shelf filter. A slope of 1 gives the steepest
+// shelf without overshoot.
+func DesignLowShelf(sampleRate, frequency, slope, gainDB float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, frequency)
+	A, sqrtA := shelfParameters(s, slope, gainDB)
+	return BiquadCoefficients{
+		B0: A * ((A + 1) - (A-1)*c + sqrtA),
+		B1: 2 * A * ((A - 1) - (A+1)*c),
+		B2: A * ((A + 1) - (A-1)*c - sqrtA),
+		A0: (A + 1) + (A-1)*c + sqrtA,
+		A1: -2 * ((A - 1) + (A+1)*c),
+		A2: (A + 1) + (A-1)*c - sqrtA,
+	}.normalized()
+}
+
+// DesignHighShelf designs a second order high shelf filter. A slope of 1 gives the steepest
+// shelf without overshoot.
+func DesignHighShelf(sampleRate, frequency, slope, gainDB float64) BiquadCoefficients {
+	s, c := biquadAngle(sampleRate, frequency)
+	A, sqrtA := shelfParameters(s, slope, gainDB)
+	return BiquadCoefficients{
+		B0: A * ((A + 1) + (A-1)*c + sqrtA),
+		B1: -2 * A * ((A - 1) + (A+1)*c),
+		B2: A * ((A + 1) + (A-1)*c - sqrtA),
+		A0: (A + 1) - (A-1)*c + sqrtA,
+		A1: 2 * ((A - 1) - (A+1)*c),
+		A2: (A + 1) - (A-1)*c - sqrtA,
+	}.normalized()
+}
+
+func biquadAngle(sampleRate, frequency float64) (float64, float64) {
+	w := 2 * math.Pi * frequency / sampleRate
+	return math.Sin(w), math.Cos(w)
+}
+
+func shelfParameters(s, slope, gainDB float64) (float64, float64) {
+	A := math.Pow(10, gainDB/40)
+	a := s / 2 * math.Sqrt((A+1/A)*(1/slope-1)+2)
+	return A, 2 * math.Sqrt(A) * a
+}
@@ -0,0 +1,69 @@
+package malgo_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func assertGainDB(t *testing.T, coefficients malgo.BiquadCoefficients, frequency, expected float64, message string) {
+	t.Helper()
+	gain := 20 * math.Log10(coefficients.Magnitude(48000, frequency))
+	if math.Abs(gain-expected) > 0.1 {
+		t.Fatalf("%s: expected %.2f dB at %.0f Hz, got %.2f dB", message, expected, frequency, gain)
+	}
+}
+
+func TestDesignLPF(t *testing.T) {
+	lpf := malgo.DesignLPF(48000, 1000, math.Sqrt2/2)
+	assertEqual(t, 1.0, lpf.A0, "Expected normalized coefficients")
+
+	// Run the designed coefficients as a direct form I filter and compare with miniaudio's ma_lpf2.
+	const length = 256
+	reference, err := malgo.LPF2ImpulseResponse(48000, 1000, math.Sqrt2/2, length)
+	assertNil(t, err, "No error expected running ma_lpf2")
+	var x1, x2, y1, y2 float64
+	for i := 0; i < length; i++ {
+		x := 0.0
+		if i == 0 {
+			x = 1
+		}
+		y := lpf.B0*x + lpf.B1*x1 + lpf.B2*x2 - lpf.A1*y1 - lpf.A2*y2
+		x1, x2, y1, y2 = x, x1, y, y1
+		if math.Abs(y-float64(reference[i])) > 1e-5 {
+			t.Fatalf("impulse response sample %d: expected %g from ma_lpf2, got %g", i, reference[i], y)
+		}
+	}
+
+	assertGainDB(t, lpf, 0, 0, "LPF passband")
+	assertGainDB(t, lpf, 1000, -3.01, "LPF cutoff")
+	assertTrue(t, lpf.Magnitude(48000, 10000) < 0.02, "LPF expected to attenuate the stopband")
+}
+
+func TestDesignFilters(t *testing.T) {
+	const q = math.Sqrt2 / 2
+
+	hpf := malgo.DesignHPF(48000, 1000, q)
+	assertGainDB(t, hpf, 1000, -3.01, "HPF cutoff")
+	assertGainDB(t, hpf, 20000, 0, "HPF passband")
+
+	bpf := malgo.DesignBPF(48000, 1000, 2)
+	assertGainDB(t, bpf, 1000, 0, "BPF center")
+
+	notch := malgo.DesignNotch(48000, 1000, 2)
+	assertTrue(t, notch.Magnitude(48000, 1000) < 1e-6, "Notch expected to reject the center frequency")
+	assertGainDB(t, notch, 0, 0, "Notch passband")
+
+	peak := malgo.DesignPeak(48000, 1000, 1, 6)
+	assertGainDB(t, peak, 1000, 6, "Peak center")
+	assertGainDB(t, peak, 0, 0, "Peak passband")
+
+	lowShelf := malgo.DesignLowShelf(48000, 1000, 1, -6)
+	assertGainDB(t, lowShelf, 0, -6, "Low shelf")
+	assertGainDB(t, lowShelf, 20000, 0, "Low shelf passband")
+
+	highShelf := malgo.DesignHighShelf(48000, 1000, 1, 4)
+	assertGainDB(t, highShelf, 23999, 4, "High shelf")
+	assertGainDB(t, highShelf, 0, 0, "High shelf passband")
+}
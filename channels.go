@@ -0,0 +1,109 @@
+package malgo
+
+// #include "malgo.h"
+import "C"
+import (
+	"math"
+)
+
+// DownmixToMono mixes interleaved frames with any number of channels down to a single channel.
+//
+// By default every channel contributes equally. When weighted is set, the channels are mixed
+// using the standard downmix weights for miniaudio's default channel layout of the given channel
+// count: front and center channels at full level, surround channels at -3 dB and LFE excluded.
+// The result is normalized by the total weight, so it saturates only if the input already does.
+func DownmixToMono(format FormatType, frames []byte, frameCount, channels int, weighted bool) ([]byte, error) {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount < 0 || len(frames) < frameCount*channels*sampleSize {
+		return nil, ErrInvalidArgs
+	}
+
+	weights := make([]float64, channels)
+	total := 0.0
+	for channel := range weights {
+		weights[channel] = 1
+		if weighted {
+			weights[channel] = downmixWeight(C.ma_channel_map_get_channel(nil, C.ma_uint32(channels), C.ma_uint32(channel)))
+		}
+		total += weights[channel]
+	}
+	if total == 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	mono := make([]byte, frameCount*sampleSize)
+	for frame := 0; frame < frameCount; frame++ {
+		sum := 0.0
+		for channel, weight := range weights {
+			sum += weight * sampleAt(format, frames, frame*channels+channel)
+		}
+		setSampleAt(format, mono, frame, sum/total)
+	}
+
+	return mono, nil
+}
+
+// MonoToStereo duplicates a single channel into interleaved stereo frames.
+func MonoToStereo(format FormatType, mono []byte, frameCount int) ([]byte, error) {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || frameCount < 0 || len(mono) < frameCount*sampleSize {
+		return nil, ErrInvalidArgs
+	}
+
+	stereo := make([]byte, frameCount*2*sampleSize)
+	for frame := 0; frame < frameCount; frame++ {
+		sample := mono[frame*sampleSize : (frame+1)*sampleSize]
+		copy(stereo[frame*2*sampleSize:], sample)
+		copy(stereo[(frame*2+1)*sampleSize:], sample)
+	}
+
+	return stereo, nil
+}
+
+// StereoToMono averages the two channels of interleaved stereo frames into a single channel.
+func StereoToMono(format FormatType, stereo []byte, frameCount int) ([]byte, error) {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || frameCount < 0 || len(stereo) < frameCount*2*sampleSize {
+		return nil, ErrInvalidArgs
+	}
+
+	mono := make([]byte, frameCount*sampleSize)
+	for frame := 0; frame < frameCount; frame++ {
+		setSampleAt(format, mono, frame, (sampleAt(format, stereo, frame*2)+sampleAt(format, stereo, frame*2+1))/2)
+	}
+
+	return mono, nil
+}
+
+// downmixWeight returns the weight of a channel position when mixing down to mono.
+func downmixWeight(position C.ma_channel) float64 {
+	switch position {
+	case C.MA_CHANNEL_MONO, C.MA_CHANNEL_FRONT_LEFT, C.MA_CHANNEL_FRONT_RIGHT, C.MA_CHANNEL_FRONT_CENTER:
+		return 1
+	case C.MA_CHANNEL_LFE:
+		return 0
+	default:
+		return math.Sqrt2 / 2
+	}
+}
+
+// ChannelMapCopy copies the channel positions of src to dst. Like the built-in copy, it copies
+// the smaller of len(dst) and len(src) channels.
+func ChannelMapCopy(dst, src []Channel) {
+	copy(dst, src)
+}
+
+// ChannelMapFind returns the index of the first occurrence of a channel position in a channel map.
+func ChannelMapFind(m []Channel, ch Channel) (index int, found bool) {
+	for i, position := range m {
+		if position == ch {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ChannelMapSwap exchanges the channel positions at indices i and j.
+func ChannelMapSwap(m []Channel, i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
@@ -0,0 +1,84 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestDownmixToMono(t *testing.T) {
+	// Two frames of 5.1 (FL, FR, FC, LFE, BL, BR).
+	in := []int16{
+		16000, 16000, 16000, 32767, 0, 0,
+		32767, 32767, 32767, 0, 32767, 32767,
+	}
+	frames := make([]byte, len(in)*2)
+	for i, sample := range in {
+		binary.LittleEndian.PutUint16(frames[i*2:], uint16(sample))
+	}
+
+	mono, err := malgo.DownmixToMono(malgo.FormatS16, frames, 2, 6, false)
+	assertNil(t, err, "No error expected downmixing")
+	assertEqual(t, 4, len(mono), "Unexpected output size")
+	assertEqual(t, int16(13461), int16(binary.LittleEndian.Uint16(mono)), "Unexpected flat average")
+
+	mono, err = malgo.DownmixToMono(malgo.FormatS16, frames, 2, 6, true)
+	assertNil(t, err, "No error expected downmixing with weights")
+	// 48000 spread over front weights of 1 and back weights of 1/sqrt(2), without the LFE.
+	assertEqual(t, int16(10874), int16(binary.LittleEndian.Uint16(mono)), "Expected LFE to be excluded")
+	assertEqual(t, int16(32767), int16(binary.LittleEndian.Uint16(mono[2:])), "Expected full scale input to stay in range")
+
+	_, err = malgo.DownmixToMono(malgo.FormatS16, frames, 3, 6, false)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected short buffer to be rejected")
+}
+
+func TestChannelMapHelpers(t *testing.T) {
+	m := []malgo.Channel{malgo.ChannelFrontLeft, malgo.ChannelFrontRight, malgo.ChannelLFE}
+
+	index, found := malgo.ChannelMapFind(m, malgo.ChannelLFE)
+	assertTrue(t, found, "Expected LFE to be found")
+	assertEqual(t, 2, index, "Unexpected LFE index")
+
+	_, found = malgo.ChannelMapFind(m, malgo.ChannelBackLeft)
+	assertTrue(t, !found, "Expected missing position not to be found")
+
+	malgo.ChannelMapSwap(m, 0, 1)
+	assertEqual(t, malgo.ChannelFrontRight, m[0], "Expected swapped channels")
+	assertEqual(t, malgo.ChannelFrontLeft, m[1], "Expected swapped channels")
+
+	dst := make([]malgo.Channel, 2)
+	malgo.ChannelMapCopy(dst, m)
+	assertEqual(t, malgo.ChannelFrontRight, dst[0], "Expected copied channels")
+	assertEqual(t, malgo.ChannelFrontLeft, dst[1], "Expected copied channels")
+}
+
+func TestMonoStereo(t *testing.T) {
+	tests := []struct {
+		format malgo.FormatType
+		stereo []byte // One frame.
+		mono   []byte
+	}{
+		{malgo.FormatU8, []byte{0x10, 0x30}, []byte{0x20}},
+		{malgo.FormatS16, []byte{0xe8, 0x03, 0xb8, 0x0b}, []byte{0xd0, 0x07}},
+		{malgo.FormatS24, []byte{0x00, 0x01, 0x00, 0x00, 0x03, 0x00}, []byte{0x00, 0x02, 0x00}},
+		{malgo.FormatS32, []byte{0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff, 0x7f}, []byte{0xff, 0xff, 0xff, 0x7f}},
+		{malgo.FormatF32, []byte{0x00, 0x00, 0x80, 0x3e, 0x00, 0x00, 0x40, 0x3f}, []byte{0x00, 0x00, 0x00, 0x3f}},
+	}
+	for _, test := range tests {
+		mono, err := malgo.StereoToMono(test.format, test.stereo, 1)
+		assertNil(t, err, "No error expected summing to mono")
+		if string(mono) != string(test.mono) {
+			t.Errorf("%v: expected mono %v, got %v", test.format, test.mono, mono)
+		}
+
+		stereo, err := malgo.MonoToStereo(test.format, test.mono, 1)
+		assertNil(t, err, "No error expected duplicating to stereo")
+		if string(stereo) != string(test.mono)+string(test.mono) {
+			t.Errorf("%v: expected both channels to equal %v, got %v", test.format, test.mono, stereo)
+		}
+	}
+
+	_, err := malgo.StereoToMono(malgo.FormatS16, make([]byte, 6), 2)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short buffer")
+}
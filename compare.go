@@ -0,0 +1,33 @@
+package malgo
+
+import (
+	"bytes"
+	"math"
+)
+
+// ComparePCM compares the first frameCount interleaved frames of a and b. It returns the largest absolute
+// difference between two samples, in the normalized [-1, 1] range, and whether the buffers are byte
+// for byte identical.
+//
+// Buffers of different lengths are never identical; the difference is then measured over the frames
+// both contain. The difference is positive infinity for an unknown format.
+func ComparePCM(format FormatType, a, b []byte, frameCount, channels int) (maxAbsDiff float64, identical bool) {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount < 0 {
+		return math.Inf(1), false
+	}
+
+	sampleCount := frameCount * channels
+	for _, frames := range [][]byte{a, b} {
+		if available := len(frames) / sampleSize; available < sampleCount {
+			sampleCount = available
+		}
+	}
+	for i := 0; i < sampleCount; i++ {
+		maxAbsDiff = math.Max(maxAbsDiff, math.Abs(sampleAt(format, a, i)-sampleAt(format, b, i)))
+	}
+
+	size := frameCount * channels * sampleSize
+	identical = len(a) == len(b) && len(a) >= size && bytes.Equal(a[:size], b[:size])
+	return maxAbsDiff, identical
+}
@@ -0,0 +1,33 @@
+package malgo_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestComparePCM(t *testing.T) {
+	formats := []malgo.FormatType{malgo.FormatU8, malgo.FormatS16, malgo.FormatS24, malgo.FormatS32, malgo.FormatF32}
+	for _, format := range formats {
+		a := malgo.GenerateChannelIdentification(format, 48000, 2, 64)
+		b := append([]byte(nil), a...)
+
+		diff, identical := malgo.ComparePCM(format, a, b, 64, 2)
+		assertEqual(t, 0.0, diff, "Expected no difference between copies")
+		assertTrue(t, identical, "Expected copies to be identical")
+
+		// Flip the most significant byte of one sample.
+		b[(10+1)*malgo.SampleSizeInBytes(format)-1] ^= 0x40
+		diff, identical = malgo.ComparePCM(format, a, b, 64, 2)
+		assertTrue(t, diff > 0.1, "Expected the changed sample to be measured")
+		assertTrue(t, !identical, "Expected changed buffers not to be identical")
+
+		diff, identical = malgo.ComparePCM(format, a, a[:len(a)-malgo.SampleSizeInBytes(format)*2], 64, 2)
+		assertEqual(t, 0.0, diff, "Expected the common frames to match")
+		assertTrue(t, !identical, "Expected buffers of different lengths not to be identical")
+	}
+
+	diff, identical := malgo.ComparePCM(malgo.FormatUnknown, nil, nil, 0, 2)
+	assertTrue(t, math.IsInf(diff, 1) && !identical, "Expected an unknown format to never match")
+}
@@ -0,0 +1,126 @@
+package malgo
+
+import (
+	"math"
+)
+
+// CompressorConfig type.
+type CompressorConfig struct {
+	Channels     int
+	SampleRate   int
+	ThresholdDB  float64 // Level above which gain reduction is applied, in dBFS.
+	Ratio        float64 // Input to output ratio above the threshold. Must be at least 1.
+	KneeDB       float64 // Width of the soft knee centered on the threshold. Zero gives a hard knee.
+	AttackMs     float64 // Time for the gain reduction to engage.
+	ReleaseMs    float64 // Time for the gain reduction to recover.
+	MakeupGainDB float64 // Gain applied after compression.
+}
+
+// Compressor is a feed-forward dynamic range compressor with a soft knee.
+//
+// The detector follows the peak of all channels, so every channel receives the same gain and
+// the stereo image is preserved. Only FormatF32 frames are supported.
+type Compressor struct {
+	config          CompressorConfig
+	attackCoeff     float64
+	releaseCoeff    float64
+	gainReductionDB float64
+}
+
+// NewCompressor creates a compressor.
+func NewCompressor(config CompressorConfig) (*Compressor, error) {
+	if config.Channels <= 0 || config.SampleRate <= 0 || config.Ratio < 1 || config.KneeDB < 0 ||
+		config.AttackMs < 0 || config.ReleaseMs < 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	return &Compressor{
+		config:       config,
+		attackCoeff:  timeConstantCoeff(config.AttackMs, config.SampleRate),
+		releaseCoeff: timeConstantCoeff(config.ReleaseMs, config.SampleRate),
+	}, nil
+}
+
+// GainReductionDB returns the current gain reduction in dB, excluding makeup gain. It is zero or negative.
+func (c *Compressor) GainReductionDB() float64 {
+	return c.gainReductionDB
+}
+
+// LatencyInFrames returns the delay the compressor adds to the signal, which is always zero: the gain
+// is computed from the current frame without lookahead.
+func (c *Compressor) LatencyInFrames() int {
+	return 0
+}
+
+// ProcessPCMFrames compresses frameCount interleaved FormatF32 frames from in and writes them to out.
+// The input and output buffers may be the same slice.
+func (c *Compressor) ProcessPCMFrames(out, in []byte, frameCount int) error {
+	channels := c.config.Channels
+	sampleCount := frameCount * channels
+	if frameCount < 0 || len(in) < sampleCount*4 || len(out) < sampleCount*4 {
+		return ErrInvalidArgs
+	}
+
+	for frame := 0; frame < frameCount; frame++ {
+		peak := 0.0
+		for channel := 0; channel < channels; channel++ {
+			peak = math.Max(peak, math.Abs(sampleAt(FormatF32, in, frame*channels+channel)))
+		}
+
+		target := c.computeGainDB(linearToDB(peak))
+		coeff := c.releaseCoeff
+		if target < c.gainReductionDB {
+			coeff = c.attackCoeff
+		}
+		c.gainReductionDB = coeff*c.gainReductionDB + (1-coeff)*target
+
+		gain := dbToLinear(c.gainReductionDB + c.config.MakeupGainDB)
+		for channel := 0; channel < channels; channel++ {
+			index := frame*channels + channel
+			setSampleAt(FormatF32, out, index, sampleAt(FormatF32, in, index)*gain)
+		}
+	}
+
+	return nil
+}
+
+func (c *Compressor) outputFrameSize() int {
+	return c.config.Channels * 4
+}
+
+// computeGainDB returns the static gain change in dB for an input level in dB.
+func (c *Compressor) computeGainDB(levelDB float64) float64 {
+	threshold := c.config.ThresholdDB
+	knee := c.config.KneeDB
+	slope := 1/c.config.Ratio - 1
+
+	overshoot := levelDB - threshold
+	switch {
+	case 2*overshoot < -knee:
+		return 0
+	case 2*math.Abs(overshoot) <= knee && knee > 0:
+		x := overshoot + knee/2
+		return slope * x * x / (2 * knee)
+	default:
+		return slope * overshoot
+	}
+}
+
+// timeConstantCoeff returns the one-pole smoothing coefficient for a time constant in milliseconds.
+func timeConstantCoeff(ms float64, sampleRate int) float64 {
+	if ms <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (ms / 1000 * float64(sampleRate)))
+}
+
+func linearToDB(v float64) float64 {
+	if v <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(v)
+}
+
+func dbToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -0,0 +1,52 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestCompressorAttackRelease(t *testing.T) {
+	const sampleRate = 48000
+	compressor, err := malgo.NewCompressor(malgo.CompressorConfig{
+		Channels:    1,
+		SampleRate:  sampleRate,
+		ThresholdDB: -20,
+		Ratio:       4,
+		KneeDB:      6,
+		AttackMs:    5,
+		ReleaseMs:   50,
+	})
+	assertNil(t, err, "No error expected creating compressor")
+
+	process := func(level float32, ms int) {
+		frameCount := sampleRate * ms / 1000
+		frames := make([]byte, frameCount*4)
+		for i := 0; i < frameCount; i++ {
+			binary.LittleEndian.PutUint32(frames[i*4:], math.Float32bits(level))
+		}
+		err := compressor.ProcessPCMFrames(frames, frames, frameCount)
+		assertNil(t, err, "No error expected processing frames")
+	}
+
+	process(0.01, 100)
+	assertTrue(t, compressor.GainReductionDB() > -0.1, "No gain reduction expected below threshold")
+
+	// A full scale transient is 20 dB over the threshold, so it settles at 15 dB of reduction.
+	process(1, 1)
+	assertTrue(t, compressor.GainReductionDB() > -15, "Gain reduction should not engage instantly")
+	process(1, 25)
+	assertTrue(t, compressor.GainReductionDB() < -14, "Gain reduction expected to engage after the attack time")
+
+	process(0.01, 10)
+	assertTrue(t, compressor.GainReductionDB() < -5, "Gain reduction should not release instantly")
+	process(0.01, 300)
+	assertTrue(t, compressor.GainReductionDB() > -0.5, "Gain reduction expected to release after the release time")
+}
+
+func TestCompressorInvalidConfig(t *testing.T) {
+	_, err := malgo.NewCompressor(malgo.CompressorConfig{Channels: 2, SampleRate: 48000, Ratio: 0.5})
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected invalid args for ratio below 1")
+}
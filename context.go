@@ -39,7 +39,8 @@ type JackContextConfig struct {
 // ContextConfig type.
 type ContextConfig struct {
 	LogCallback         *[0]byte
-	ThreadPriority      ThreadPriority
+	ThreadPriority      ThreadPriority // Priority of the audio threads of every device in the context. Realtime usually needs elevated privileges and may be silently denied by the OS.
+	ThreadStackSize     int            // Stack size in bytes of the audio threads. Zero uses the OS default.
 	PUserData           *byte
 	AllocationCallbacks AllocationCallbacks
 	Alsa                AlsaContextConfig
@@ -49,8 +50,13 @@ type ContextConfig struct {
 }
 
 func (d *ContextConfig) toC() (C.ma_context_config, error) {
+	if d.ThreadStackSize < 0 {
+		return C.ma_context_config{}, ErrInvalidArgs
+	}
+
 	ctxConfig := C.ma_context_config_init()
 	ctxConfig.threadPriority = C.ma_thread_priority(d.ThreadPriority)
+	ctxConfig.threadStackSize = C.size_t(d.ThreadStackSize)
 	ctxConfig.pUserData = unsafe.Pointer(d.PUserData)
 	ctxConfig.allocationCallbacks.pUserData = unsafe.Pointer(d.AllocationCallbacks.PUserData)
 	ctxConfig.allocationCallbacks.onMalloc = d.AllocationCallbacks.OnMalloc
@@ -98,6 +104,24 @@ func (ctx Context) Uninit() error {
 
 // Devices retrieves basic information about every active playback or capture device.
 func (ctx Context) Devices(kind DeviceType) ([]DeviceInfo, error) {
+	playback, capture, err := ctx.RefreshDevices()
+	if err != nil {
+		return nil, err
+	}
+	if kind == Capture {
+		return capture, nil
+	}
+	return playback, nil
+}
+
+// RefreshDevices enumerates the devices once and returns both the playback and the capture devices.
+// Calling Devices for each type enumerates twice, so a device plugged in between the two calls may only
+// show up in one of the lists; RefreshDevices returns a consistent snapshot of the current hardware.
+//
+// It is safe to call from any goroutine, also while devices of the context are being initialized,
+// started or are running: device initialization does not use the enumeration, and enumerations are
+// serialized with each other and with Devices, as miniaudio requires. The returned slices are copies.
+func (ctx Context) RefreshDevices() (playback, capture []DeviceInfo, err error) {
 	contextMutex.Lock()
 	defer contextMutex.Unlock()
 
@@ -109,24 +133,22 @@ func (ctx Context) Devices(kind DeviceType) ([]DeviceInfo, error) {
 	result := C.ma_context_get_devices(ctx.cptr(),
 		&playbackDevices, &playbackDeviceCount,
 		&captureDevices, &captureDeviceCount)
-	err := errorFromResult(result)
-	if err != nil {
-		return nil, err
-	}
-	devices := playbackDevices
-	deviceCount := int(playbackDeviceCount)
-	if kind == Capture {
-		devices = captureDevices
-		deviceCount = int(captureDeviceCount)
+	if err := errorFromResult(result); err != nil {
+		return nil, nil, err
 	}
+
+	return deviceInfos(playbackDevices, int(playbackDeviceCount)), deviceInfos(captureDevices, int(captureDeviceCount)), nil
+}
+
+// deviceInfos copies an array of device infos owned by the context.
+func deviceInfos(devices *C.ma_device_info, deviceCount int) []DeviceInfo {
 	info := make([]DeviceInfo, deviceCount)
 	deviceInfoAddr := unsafe.Pointer(devices)
 	for i := 0; i < deviceCount; i++ {
 		info[i] = deviceInfoFromPointer(deviceInfoAddr)
 		deviceInfoAddr = unsafe.Add(deviceInfoAddr, rawDeviceInfoSize)
 	}
-
-	return info, nil
+	return info
 }
 
 // DeviceInfo retrieves information about a device of the given type, with the specified ID and share mode.
@@ -142,6 +164,67 @@ func (ctx Context) DeviceInfo(kind DeviceType, id DeviceID, mode ShareMode) (Dev
 	return deviceInfoFromPointer(unsafe.Pointer(&info)), nil
 }
 
+// SupportsFormat reports whether the device natively supports the given data format, in which case
+// no conversion is needed. Zero fields in the format and in the native formats of the device match any value.
+//
+// The second return value is the closest native format, preferring the same sample rate, then the same
+// sample format, then the nearest channel count. It is the zero value if the device reports no native formats.
+func (ctx Context) SupportsFormat(kind DeviceType, id DeviceID, format DataFormat) (bool, DataFormat, error) {
+	info, err := ctx.DeviceInfo(kind, id, Shared)
+	if err != nil {
+		return false, DataFormat{}, err
+	}
+
+	supported, closest := closestNativeFormat(info.Formats, format)
+	return supported, closest, nil
+}
+
+// closestNativeFormat is the matching of SupportsFormat over a list of native formats.
+func closestNativeFormat(natives []DataFormat, format DataFormat) (bool, DataFormat) {
+	var closest DataFormat
+	bestScore := -1
+	for _, native := range natives {
+		candidate := DataFormat{
+			Format:     FormatType(pickNonZero(uint32(native.Format), uint32(format.Format))),
+			Channels:   pickNonZero(native.Channels, format.Channels),
+			SampleRate: pickNonZero(native.SampleRate, format.SampleRate),
+			Flags:      native.Flags,
+		}
+
+		score := 0
+		if format.SampleRate != 0 {
+			score += absDiff(candidate.SampleRate, format.SampleRate) * 1000
+		}
+		if format.Format != FormatUnknown && candidate.Format != format.Format {
+			score += 500
+		}
+		if format.Channels != 0 {
+			score += absDiff(candidate.Channels, format.Channels)
+		}
+
+		if bestScore < 0 || score < bestScore {
+			bestScore = score
+			closest = candidate
+		}
+	}
+
+	return bestScore == 0, closest
+}
+
+func pickNonZero(value, fallback uint32) uint32 {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+func absDiff(a, b uint32) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
 var contextMutex sync.Mutex
 var logProcMap = make(map[*C.ma_context]LogProc)
 
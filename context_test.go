@@ -25,6 +25,19 @@ func TestContextLifecycle(t *testing.T) {
 	assertEqual(t, malgo.Context{}, ctx.Context, "Expected context value to be reset")
 }
 
+func TestContextThreadConfig(t *testing.T) {
+	config := malgo.ContextConfig{ThreadPriority: malgo.ThreadPriorityRealtime, ThreadStackSize: 1 << 20}
+
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, config, nil)
+	assertNil(t, err, "No error expected initializing context with thread config")
+	_ = ctx.Uninit()
+	ctx.Free()
+
+	config.ThreadStackSize = -1
+	_, err = malgo.InitContext(nil, config, nil)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a negative stack size")
+}
+
 func TestContextDeviceEnumeration(t *testing.T) {
 	if *testWithHardware {
 		t.Log("Running test expecting devices\n")
@@ -49,6 +62,92 @@ func TestContextDeviceEnumeration(t *testing.T) {
 	if *testWithHardware {
 		assertTrue(t, len(captureDevices) > 0, "No capture devices found")
 	}
+
+	refreshedPlayback, refreshedCapture, err := ctx.RefreshDevices()
+	assertNil(t, err, "No error expected refreshing devices")
+	assertEqual(t, len(playbackDevices), len(refreshedPlayback), "Expected the same playback devices after a refresh")
+	assertEqual(t, len(captureDevices), len(refreshedCapture), "Expected the same capture devices after a refresh")
+	for i := range playbackDevices {
+		assertEqual(t, playbackDevices[i].ID, refreshedPlayback[i].ID, "Expected the same playback device IDs after a refresh")
+		assertEqual(t, playbackDevices[i].Name(), refreshedPlayback[i].Name(), "Expected the same playback device names after a refresh")
+	}
+	for i := range captureDevices {
+		assertEqual(t, captureDevices[i].ID, refreshedCapture[i].ID, "Expected the same capture device IDs after a refresh")
+		assertEqual(t, captureDevices[i].Name(), refreshedCapture[i].Name(), "Expected the same capture device names after a refresh")
+	}
+}
+
+func TestEnabledBackends(t *testing.T) {
+	backends := malgo.EnabledBackends()
+	assertTrue(t, len(backends) > 0, "At least one backend expected to be enabled")
+
+	ctx, err := malgo.InitContext(backends, malgo.ContextConfig{}, nil)
+	assertNil(t, err, "No error expected initializing context with enabled backends")
+	_ = ctx.Uninit()
+	ctx.Free()
+}
+
+func TestContextSupportsFormat(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	assertNil(t, err, "No error expected initializing context")
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	devices, err := ctx.Devices(malgo.Playback)
+	assertNil(t, err, "No error expected querying playback devices")
+	assertTrue(t, len(devices) > 0, "Null backend expected to provide a playback device")
+
+	info, err := ctx.DeviceInfo(malgo.Playback, devices[0].ID, malgo.Shared)
+	assertNil(t, err, "No error expected querying device info")
+	assertTrue(t, len(info.Formats) > 0, "Native formats expected")
+	native := info.Formats[0]
+
+	supported, closest, err := ctx.SupportsFormat(malgo.Playback, devices[0].ID, native)
+	assertNil(t, err, "No error expected checking a native format")
+	assertTrue(t, supported, "Native format expected to be supported")
+	assertEqual(t, native, closest, "Expected the native format as closest match")
+
+	requested := native
+	requested.SampleRate = 12345
+	supported, _, err = ctx.SupportsFormat(malgo.Playback, devices[0].ID, requested)
+	assertNil(t, err, "No error expected checking a non-native format")
+	assertTrue(t, native.SampleRate == 0 || !supported, "Non-native sample rate expected to be unsupported")
+}
+
+func TestClosestNativeFormat(t *testing.T) {
+	natives := []malgo.DataFormat{
+		{Format: malgo.FormatS16, Channels: 2, SampleRate: 44100},
+		{Format: malgo.FormatF32, Channels: 2, SampleRate: 48000},
+		{Format: malgo.FormatS16, Channels: 6, SampleRate: 48000},
+	}
+
+	supported, closest := malgo.ClosestNativeFormat(natives, natives[1])
+	assertTrue(t, supported, "Native format expected to be supported")
+	assertEqual(t, natives[1], closest, "Expected the native format as closest match")
+
+	supported, closest = malgo.ClosestNativeFormat(natives, malgo.DataFormat{Format: malgo.FormatS16, Channels: 2})
+	assertTrue(t, supported, "Zero fields expected to match any value")
+	assertEqual(t, natives[0], closest, "Expected the first full match")
+
+	// The same sample rate is preferred over the same sample format, then the nearest channel count.
+	supported, closest = malgo.ClosestNativeFormat(natives, malgo.DataFormat{Format: malgo.FormatS16, Channels: 2, SampleRate: 48000})
+	assertTrue(t, !supported, "Non-native format expected to be unsupported")
+	assertEqual(t, natives[2], closest, "Expected the native format with the same rate and sample format")
+
+	supported, closest = malgo.ClosestNativeFormat(natives, malgo.DataFormat{Format: malgo.FormatS32, Channels: 2, SampleRate: 96000})
+	assertTrue(t, !supported, "Non-native sample rate expected to be unsupported")
+	assertEqual(t, natives[1], closest, "Expected the native format with the nearest rate")
+
+	// Zero fields of a native format take the requested value.
+	supported, closest = malgo.ClosestNativeFormat([]malgo.DataFormat{{}}, natives[0])
+	assertTrue(t, supported, "Native format with zero fields expected to match anything")
+	assertEqual(t, natives[0], closest, "Expected the requested values in place of zero fields")
+
+	supported, closest = malgo.ClosestNativeFormat(nil, natives[0])
+	assertTrue(t, !supported, "No format expected to be supported without native formats")
+	assertEqual(t, malgo.DataFormat{}, closest, "Expected the zero value without native formats")
 }
 
 func assertEqual(t *testing.T, a interface{}, b interface{}, message string) {
@@ -3,9 +3,27 @@ package malgo
 // #include "malgo.h"
 import "C"
 import (
+	"fmt"
+	"math"
+	"sync"
 	"unsafe"
 )
 
+// DefaultMaxSampleRateRatio is the largest ratio between input and output sample rates accepted by
+// InitConverter when ConverterConfig.MaxSampleRateRatio is not set.
+const DefaultMaxSampleRateRatio = 1000
+
+// MaxChannels is the largest channel count supported by the converter and devices.
+//
+// This is miniaudio's MA_MAX_CHANNELS, which defaults to 254. That covers ambisonics up to
+// 14th order, and it cannot be raised further because channel positions are stored as bytes.
+const MaxChannels = C.MA_MAX_CHANNELS
+
+// DefaultLpfOrder is miniaudio's MA_DEFAULT_RESAMPLER_LPF_ORDER, the order of the low-pass filter it
+// gives the linear resampler by default, which DefaultDeviceConfig uses. Set it in
+// ResampleLinearConfig.LpfOrder of a ConverterConfig to resample with the same filter.
+const DefaultLpfOrder = 4
+
 type ConverterConfig struct {
 	FormatIn       FormatType
 	FormatOut      FormatType
@@ -15,13 +33,238 @@ type ConverterConfig struct {
 	SampleRateOut  int
 	DitherMode     DitherModeType
 	ChannelMixMode ChannelMixModeType
-	Resampling     ResampleConfig
+	Resampling     ResampleConfig // The zero value resamples without a low-pass filter; see ResampleLinearConfig.LpfOrder.
+
+	// AllowDynamicSampleRate enables changing the rate with SetRate and SetRateRatio after initialization.
+	AllowDynamicSampleRate bool
+
+	// RateChangeSmoothFrames spreads changes made with SetRate and SetRateRatio over this many input
+	// frames by moving the ratio in small steps instead of switching at once, which avoids clicks during
+	// rate automation. It adds no latency, but ProcessFrames calls into miniaudio once every 32 input
+	// frames until the new rate is reached. Zero switches immediately.
+	RateChangeSmoothFrames int
+
+	// MaxSampleRateRatio limits the ratio between the input and output sample rates, in either
+	// direction. Zero means DefaultMaxSampleRateRatio.
+	MaxSampleRateRatio int
+
+	// DitherSeed seeds the dither noise so that converting the same input produces identical output.
+	// The output of a seeded converter only depends on its configuration and on the frames passed to
+	// it since InitConverter or Reinit, even while other converters dither concurrently. Zero keeps the
+	// shared, unseeded noise generator. It has no effect with DitherModeNone.
+	DitherSeed uint32
+
+	// OnFrames is called by ProcessFrames with the frames it has just written to the output buffer.
+	// It runs synchronously on the caller's goroutine, and the slice must not be modified or retained.
+	OnFrames func(out []byte, frameCount int)
+
+	// ChannelMapIn and ChannelMapOut set the channel positions of the input and output frames. They must
+	// have ChannelsIn and ChannelsOut entries; nil selects miniaudio's default layout.
+	ChannelMapIn  []Channel
+	ChannelMapOut []Channel
+
+	// ChannelWeights sets the gain of every input channel in every output channel, indexed [in][out].
+	// It is required by, and only allowed with, ChannelMixModeCustomWeights. miniaudio does not apply the
+	// weights when the input and output channel maps are identical, or when either side is mono.
+	ChannelWeights [][]float32
+
+	// HighPrecision resamples in float64 in Go instead of with miniaudio, which converts to 32-bit float
+	// and interpolates linearly. Samples are read at full precision, filtered with a 64-tap windowed sinc
+	// and rounded once to the output format, which lowers the noise floor when resampling 24 or 32-bit
+	// audio. The output is time aligned with the input, and LatencyInFrames is zero.
+	//
+	// It costs 64 multiply-adds per output sample, about ten times the CPU time of the default resampler,
+	// and holds back 32 input frames until the frames after them are passed in. It requires the same
+	// channel count and map on input and output, and does not support dithering or a dynamic rate. It has
+	// no effect when the sample rates are equal.
+	HighPrecision bool
+
+	// Unexposed: calculateLFEFromSpatialChannels
+}
+
+func (config *ConverterConfig) maxSampleRateRatio() int {
+	if config.MaxSampleRateRatio == 0 {
+		return DefaultMaxSampleRateRatio
+	}
+	return config.MaxSampleRateRatio
+}
+
+// checkSampleRates rejects sample rates miniaudio would fail on with an unspecific error.
+func (config *ConverterConfig) checkSampleRates() error {
+	if config.SampleRateIn <= 0 {
+		return invalidConfig("SampleRateIn %d is not positive", config.SampleRateIn)
+	}
+	if config.SampleRateOut <= 0 {
+		return invalidConfig("SampleRateOut %d is not positive", config.SampleRateOut)
+	}
+	return nil
+}
+
+// check reports the first inconsistency of the configuration, wrapping ErrInvalidArgs.
+func (config *ConverterConfig) check() error {
+	if config.ChannelsIn > MaxChannels || config.ChannelsOut > MaxChannels {
+		return invalidConfig("channel count exceeds MaxChannels (%d)", MaxChannels)
+	}
+
+	maxRatio := config.maxSampleRateRatio()
+	if maxRatio < 0 {
+		return invalidConfig("MaxSampleRateRatio is negative")
+	}
+
+	low, high := config.SampleRateIn, config.SampleRateOut
+	if low > high {
+		low, high = high, low
+	}
+	if low > 0 && high > low*maxRatio {
+		return invalidConfig("sample rate ratio exceeds %d", maxRatio)
+	}
+
+	if config.ChannelMapIn != nil && len(config.ChannelMapIn) != config.ChannelsIn {
+		return invalidConfig("ChannelMapIn has %d channels, ChannelsIn is %d", len(config.ChannelMapIn), config.ChannelsIn)
+	}
+	if config.ChannelMapOut != nil && len(config.ChannelMapOut) != config.ChannelsOut {
+		return invalidConfig("ChannelMapOut has %d channels, ChannelsOut is %d", len(config.ChannelMapOut), config.ChannelsOut)
+	}
+
+	if config.RateChangeSmoothFrames < 0 {
+		return invalidConfig("RateChangeSmoothFrames is negative")
+	}
+
+	if config.HighPrecision {
+		if config.ChannelsIn != config.ChannelsOut || config.ChannelWeights != nil || !channelMapsEqual(config.ChannelMapIn, config.ChannelMapOut) {
+			return invalidConfig("HighPrecision requires the same channels on input and output")
+		}
+		if config.DitherMode != DitherModeNone {
+			return invalidConfig("HighPrecision does not support dithering")
+		}
+		if config.AllowDynamicSampleRate {
+			return invalidConfig("HighPrecision does not support AllowDynamicSampleRate")
+		}
+	}
+
+	customWeights := config.ChannelMixMode == ChannelMixModeCustomWeights
+	if customWeights && config.ChannelWeights == nil {
+		return invalidConfig("ChannelMixModeCustomWeights requires ChannelWeights")
+	}
+	if !customWeights && config.ChannelWeights != nil {
+		return invalidConfig("ChannelWeights requires ChannelMixModeCustomWeights")
+	}
+	if config.ChannelWeights != nil {
+		if len(config.ChannelWeights) != config.ChannelsIn {
+			return invalidConfig("ChannelWeights has %d input channels, ChannelsIn is %d", len(config.ChannelWeights), config.ChannelsIn)
+		}
+		for in, weights := range config.ChannelWeights {
+			if len(weights) != config.ChannelsOut {
+				return invalidConfig("ChannelWeights[%d] has %d output channels, ChannelsOut is %d", in, len(weights), config.ChannelsOut)
+			}
+		}
+	}
+
+	return nil
+}
+
+// channelMapsEqual reports whether two channel maps are the same. A nil map only equals another nil map.
+func channelMapsEqual(a, b []Channel) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
 
-	// Unexposed: pChannelMapIn, pChannelMapOut, calculateLFEFromSpatialChannels, ppChannelWeights, allowDynamicSampleRate
+func invalidConfig(format string, args ...interface{}) error {
+	return fmt.Errorf("malgo: %s: %w", fmt.Sprintf(format, args...), ErrInvalidArgs)
+}
+
+// toC returns the C configuration. Channel maps and weights are copied to C memory, which stays valid
+// until the returned function is called; the converter keeps its own copy once initialized.
+func (config *ConverterConfig) toC() (C.ma_data_converter_config, func()) {
+	configC := C.ma_data_converter_config_init_default()
+	configC.formatIn = C.ma_format(config.FormatIn)
+	configC.formatOut = C.ma_format(config.FormatOut)
+	configC.channelsIn = C.ma_uint32(config.ChannelsIn)
+	configC.channelsOut = C.ma_uint32(config.ChannelsOut)
+	configC.sampleRateIn = C.ma_uint32(config.SampleRateIn)
+	configC.sampleRateOut = C.ma_uint32(config.SampleRateOut)
+	configC.ditherMode = C.ma_dither_mode(config.DitherMode)
+	if config.AllowDynamicSampleRate {
+		configC.allowDynamicSampleRate = C.MA_TRUE
+	}
+	configC.resampling.algorithm = C.ma_resample_algorithm(config.Resampling.Algorithm)
+	configC.resampling.linear.lpfOrder = C.uint(config.Resampling.Linear.LpfOrder)
+	configC.channelMixMode = C.ma_channel_mix_mode(config.ChannelMixMode)
+
+	var allocations []unsafe.Pointer
+	alloc := func(size int) unsafe.Pointer {
+		ptr := C.ma_malloc(C.size_t(size), nil)
+		allocations = append(allocations, ptr)
+		return ptr
+	}
+	if len(config.ChannelMapIn) > 0 {
+		configC.pChannelMapIn = (*C.ma_channel)(alloc(len(config.ChannelMapIn)))
+		copy(unsafe.Slice((*Channel)(unsafe.Pointer(configC.pChannelMapIn)), len(config.ChannelMapIn)), config.ChannelMapIn)
+	}
+	if len(config.ChannelMapOut) > 0 {
+		configC.pChannelMapOut = (*C.ma_channel)(alloc(len(config.ChannelMapOut)))
+		copy(unsafe.Slice((*Channel)(unsafe.Pointer(configC.pChannelMapOut)), len(config.ChannelMapOut)), config.ChannelMapOut)
+	}
+	if len(config.ChannelWeights) > 0 {
+		rows := unsafe.Slice((**C.float)(alloc(len(config.ChannelWeights)*int(unsafe.Sizeof(uintptr(0))))), len(config.ChannelWeights))
+		for in, weights := range config.ChannelWeights {
+			rows[in] = (*C.float)(alloc(len(weights) * 4))
+			row := unsafe.Slice((*float32)(unsafe.Pointer(rows[in])), len(weights))
+			copy(row, weights)
+		}
+		configC.ppChannelWeights = &rows[0]
+	}
+
+	return configC, func() {
+		for _, ptr := range allocations {
+			C.ma_free(ptr, nil)
+		}
+	}
 }
 
 type Converter struct {
-	ptr *unsafe.Pointer
+	ptr               *unsafe.Pointer
+	config            ConverterConfig
+	ditherState       C.ma_int32
+	outputFrameCursor int64
+	processBuffer     []byte
+	partialFrame      []byte
+	alignBuffer       []byte
+	passthrough       bool
+	ramp              *rateRamp
+	highPrecision     *highPrecisionResampler
+}
+
+// rateRampChunk is the number of input frames converted at the same ratio while a rate change is smoothed.
+const rateRampChunk = 32
+
+// rateRamp tracks a rate change spread over RateChangeSmoothFrames input frames.
+type rateRamp struct {
+	from, to      float64
+	position      int
+	length        int
+	sampleRateIn  int // Rates to set at the end of the ramp, or zero when changed with SetRateRatio.
+	sampleRateOut int
+}
+
+// ditherMutex serializes every conversion that dithers, as miniaudio draws the dither noise of all
+// converters from one global generator. Devices never dither, so their conversions do not touch it.
+var ditherMutex sync.Mutex
+
+// ditherStateFromSeed maps a seed onto a valid, non-zero state of the dither noise generator.
+func ditherStateFromSeed(seed uint32) C.ma_int32 {
+	return C.ma_int32(seed%(math.MaxInt32-1) + 1)
+}
+
+func (c *Converter) isDitherSeeded() bool {
+	return c.config.DitherSeed != 0 && c.config.DitherMode != DitherModeNone
 }
 
 // InitConverter initializes a converter.
@@ -32,31 +275,37 @@ type Converter struct {
 //
 // It is very similar to the resampling API.
 //
+// A sample rate of zero or below is rejected with an error wrapping ErrInvalidArgs that names the rate.
+// Any positive rate is accepted; use ValidateSampleRate to also check the range of untrusted rates.
+//
 // The returned instance has to be cleaned up using Uninit().
 func InitConverter(config ConverterConfig) (*Converter, error) {
+	if err := config.checkSampleRates(); err != nil {
+		return nil, err
+	}
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+
 	ptr := C.ma_malloc(C.sizeof_ma_data_converter, nil)
 	converter := Converter{
-		ptr: &ptr,
+		ptr:         &ptr,
+		config:      config,
+		ditherState: ditherStateFromSeed(config.DitherSeed),
 	}
 	if uintptr(*converter.ptr) == 0 {
 		return nil, ErrOutOfMemory
 	}
 
-	configC := C.ma_data_converter_config_init_default()
-	configC.formatIn = C.ma_format(config.FormatIn)
-	configC.formatOut = C.ma_format(config.FormatOut)
-	configC.channelsIn = C.ma_uint32(config.ChannelsIn)
-	configC.channelsOut = C.ma_uint32(config.ChannelsOut)
-	configC.sampleRateIn = C.ma_uint32(config.SampleRateIn)
-	configC.sampleRateOut = C.ma_uint32(config.SampleRateOut)
-	configC.resampling.algorithm = C.ma_resample_algorithm(config.Resampling.Algorithm)
-	configC.resampling.linear.lpfOrder = C.uint(config.Resampling.Linear.LpfOrder)
-
+	configC, release := config.toC()
+	defer release()
 	result := C.ma_data_converter_init(&configC, nil, converter.cptr())
 	if result != 0 {
 		C.ma_free(ptr, nil)
 		return nil, errorFromResult(result)
 	}
+	converter.passthrough = converter.cptr().isPassthrough != 0
+	converter.highPrecision = config.highPrecisionResampler()
 
 	return &converter, nil
 }
@@ -67,6 +316,53 @@ func (c *Converter) Uninit() {
 	c.free()
 }
 
+// Reinit reinitializes the converter with a new configuration.
+//
+// The new state is initialized next to the current one and only replaces it on success, so on error
+// the converter keeps working with its previous configuration. Any frames buffered in the resampler
+// are discarded.
+func (c *Converter) Reinit(config ConverterConfig) error {
+	if err := config.checkSampleRates(); err != nil {
+		return err
+	}
+	if err := config.check(); err != nil {
+		return err
+	}
+	configC, release := config.toC()
+	defer release()
+
+	ptr := C.ma_malloc(C.sizeof_ma_data_converter, nil)
+	if ptr == nil {
+		return ErrOutOfMemory
+	}
+	result := C.ma_data_converter_init(&configC, nil, (*C.ma_data_converter)(ptr))
+	if result != 0 {
+		C.ma_free(ptr, nil)
+		return errorFromResult(result)
+	}
+
+	C.ma_data_converter_uninit(c.cptr(), nil)
+	C.ma_free(*c.ptr, nil)
+	*c.ptr = ptr
+
+	c.config = config
+	c.ditherState = ditherStateFromSeed(config.DitherSeed)
+	c.partialFrame = c.partialFrame[:0]
+	c.passthrough = c.cptr().isPassthrough != 0
+	c.ramp = nil
+	c.highPrecision = config.highPrecisionResampler()
+	return nil
+}
+
+// highPrecisionResampler returns the float64 resampler of a HighPrecision configuration, or nil if the
+// configuration does not resample in Go.
+func (config *ConverterConfig) highPrecisionResampler() *highPrecisionResampler {
+	if !config.HighPrecision || config.SampleRateIn == config.SampleRateOut {
+		return nil
+	}
+	return newHighPrecisionResampler(*config)
+}
+
 func (c Converter) free() {
 	if c.ptr != nil {
 		C.ma_free(*c.ptr, nil)
@@ -77,8 +373,197 @@ func (c Converter) cptr() *C.ma_data_converter {
 	return (*C.ma_data_converter)(*c.ptr)
 }
 
+// SetDitherMode changes the dither mode used when converting to a format with fewer bits. It takes
+// effect with the next call to ProcessFrames; miniaudio reads the mode on every call, so the converter
+// is not reinitialized and the resampler state is kept. A DitherSeed keeps applying to the new mode.
+// ErrInvalidOperation is returned when enabling dither on a HighPrecision converter, which does not dither.
+func (c *Converter) SetDitherMode(mode DitherModeType) error {
+	if mode > DitherModeTriangle {
+		return ErrInvalidArgs
+	}
+	if c.config.HighPrecision && mode != DitherModeNone {
+		return ErrInvalidOperation
+	}
+	c.cptr().ditherMode = C.ma_dither_mode(mode)
+	c.config.DitherMode = mode
+	return nil
+}
+
+// DitherMode returns the current dither mode.
+func (c *Converter) DitherMode() DitherModeType {
+	return c.config.DitherMode
+}
+
+// SetRate changes the input and output sample rates of a converter initialized with AllowDynamicSampleRate.
+//
+// With RateChangeSmoothFrames set, the change is spread over the following calls to ProcessFrames.
+// ErrInvalidOperation is returned for a HighPrecision converter, whose rates are fixed.
+func (c *Converter) SetRate(sampleRateIn, sampleRateOut int) error {
+	if c.config.HighPrecision {
+		return ErrInvalidOperation
+	}
+	config := c.config
+	config.SampleRateIn = sampleRateIn
+	config.SampleRateOut = sampleRateOut
+	if sampleRateIn <= 0 || sampleRateOut <= 0 || config.check() != nil {
+		return ErrInvalidArgs
+	}
+
+	if c.config.RateChangeSmoothFrames > 0 && c.config.AllowDynamicSampleRate {
+		c.startRamp(float64(sampleRateIn)/float64(sampleRateOut), sampleRateIn, sampleRateOut)
+		return nil
+	}
+	c.ramp = nil
+	result := C.ma_data_converter_set_rate(c.cptr(), C.ma_uint32(sampleRateIn), C.ma_uint32(sampleRateOut))
+	return errorFromResult(result)
+}
+
+// SetRateRatio changes the input to output sample rate ratio of a converter initialized with AllowDynamicSampleRate.
+// The ratio is applied with a precision of 1/1000.
+//
+// With RateChangeSmoothFrames set, the change is spread over the following calls to ProcessFrames.
+// ErrInvalidOperation is returned for a HighPrecision converter, whose rates are fixed.
+func (c *Converter) SetRateRatio(ratio float32) error {
+	if c.config.HighPrecision {
+		return ErrInvalidOperation
+	}
+	maxRatio := float32(c.config.maxSampleRateRatio())
+	if ratio <= 0 || ratio > maxRatio || 1/ratio > maxRatio {
+		return ErrInvalidArgs
+	}
+
+	if c.config.RateChangeSmoothFrames > 0 && c.config.AllowDynamicSampleRate {
+		c.startRamp(float64(ratio), 0, 0)
+		return nil
+	}
+	c.ramp = nil
+	result := C.ma_data_converter_set_rate_ratio(c.cptr(), C.float(ratio))
+	return errorFromResult(result)
+}
+
+// startRamp starts smoothing a rate change from the current ratio, or from the ratio reached so far
+// if a previous change is still being smoothed.
+func (c *Converter) startRamp(ratio float64, sampleRateIn, sampleRateOut int) {
+	c.ramp = &rateRamp{
+		from:          c.RateRatio(),
+		to:            ratio,
+		length:        c.config.RateChangeSmoothFrames,
+		sampleRateIn:  sampleRateIn,
+		sampleRateOut: sampleRateOut,
+	}
+}
+
+// advanceRamp applies the ratio for the current position of the ramp, or the final rate once it is complete.
+func (c *Converter) advanceRamp() error {
+	ramp := c.ramp
+	if ramp.position >= ramp.length {
+		c.ramp = nil
+		if ramp.sampleRateIn > 0 {
+			return errorFromResult(C.ma_data_converter_set_rate(c.cptr(), C.ma_uint32(ramp.sampleRateIn), C.ma_uint32(ramp.sampleRateOut)))
+		}
+		return errorFromResult(C.ma_data_converter_set_rate_ratio(c.cptr(), C.float(ramp.to)))
+	}
+
+	progress := float64(ramp.position+rateRampChunk/2) / float64(ramp.length)
+	ratio := ramp.from + (ramp.to-ramp.from)*math.Min(progress, 1)
+	return errorFromResult(C.ma_data_converter_set_rate_ratio(c.cptr(), C.float(ratio)))
+}
+
+// convertRamped converts frames in chunks of rateRampChunk input frames while a rate change is smoothed.
+func (c *Converter) convertRamped(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, int, error) {
+	inFrameSize := FrameSizeInBytes(c.config.FormatIn, c.config.ChannelsIn)
+	outFrameSize := c.outputFrameSize()
+
+	read, written := 0, 0
+	for c.ramp != nil && read < frameCountIn && written < frameCountOut {
+		if err := c.advanceRamp(); err != nil {
+			return read, written, err
+		}
+		if c.ramp == nil {
+			break
+		}
+
+		chunk := rateRampChunk
+		if remaining := c.ramp.length - c.ramp.position; remaining < chunk {
+			chunk = remaining
+		}
+		if remaining := frameCountIn - read; remaining < chunk {
+			chunk = remaining
+		}
+
+		var in, out []byte
+		if len(pFramesIn) > 0 {
+			in = pFramesIn[read*inFrameSize:]
+		}
+		if len(pFramesOut) > 0 {
+			out = pFramesOut[written*outFrameSize:]
+		}
+		chunkRead, chunkWritten, err := c.convertFrames(in, chunk, out, frameCountOut-written)
+		if err != nil {
+			return read, written, err
+		}
+		read += chunkRead
+		written += chunkWritten
+		c.ramp.position += chunkRead
+		if chunkRead == 0 {
+			break
+		}
+	}
+	if c.ramp != nil && c.ramp.position >= c.ramp.length {
+		if err := c.advanceRamp(); err != nil {
+			return read, written, err
+		}
+	}
+
+	if read < frameCountIn && written < frameCountOut {
+		var in, out []byte
+		if len(pFramesIn) > 0 {
+			in = pFramesIn[read*inFrameSize:]
+		}
+		if len(pFramesOut) > 0 {
+			out = pFramesOut[written*outFrameSize:]
+		}
+		restRead, restWritten, err := c.convertFrames(in, frameCountIn-read, out, frameCountOut-written)
+		if err != nil {
+			return read, written, err
+		}
+		read += restRead
+		written += restWritten
+	}
+	return read, written, nil
+}
+
+// RateRatio returns the current input to output sample rate ratio, including changes made with SetRate and SetRateRatio.
+func (c *Converter) RateRatio() float64 {
+	sampleRateIn, sampleRateOut := c.sampleRates()
+	if sampleRateOut == 0 {
+		return 0
+	}
+	return float64(sampleRateIn) / float64(sampleRateOut)
+}
+
+// sampleRates returns the current input and output sample rates.
+func (c *Converter) sampleRates() (int, int) {
+	converter := c.cptr()
+	if converter.hasResampler != 0 {
+		return int(converter.resampler.sampleRateIn), int(converter.resampler.sampleRateOut)
+	}
+	return int(converter.sampleRateIn), int(converter.sampleRateOut)
+}
+
 // RequiredInputFrameCount returns how many input frames you need to provide in order to output a specific number of output frames.
 func (c *Converter) RequiredInputFrameCount(outputFrameCount int) (int, error) {
+	if outputFrameCount < 0 {
+		return 0, ErrInvalidArgs
+	}
+	sampleRateIn, sampleRateOut := c.sampleRates()
+	if c.overflows(outputFrameCount, sampleRateIn, sampleRateOut) {
+		return 0, ErrTooBig
+	}
+	if c.highPrecision != nil {
+		return c.highPrecision.requiredInputFrameCount(outputFrameCount), nil
+	}
+
 	var cInputFrameCount C.ma_uint64
 	var cOutputFrameCount C.ma_uint64 = C.ma_uint64(outputFrameCount)
 
@@ -92,6 +577,17 @@ func (c *Converter) RequiredInputFrameCount(outputFrameCount int) (int, error) {
 
 // ExpectOutputFrameCount returns how many output frames you can expect to get from a specific number of input frames.
 func (c *Converter) ExpectOutputFrameCount(inputFrameCount int) (int, error) {
+	if inputFrameCount < 0 {
+		return 0, ErrInvalidArgs
+	}
+	sampleRateIn, sampleRateOut := c.sampleRates()
+	if c.overflows(inputFrameCount, sampleRateOut, sampleRateIn) {
+		return 0, ErrTooBig
+	}
+	if c.highPrecision != nil {
+		return c.highPrecision.expectOutputFrameCount(inputFrameCount), nil
+	}
+
 	var cInputFrameCount C.ma_uint64 = C.ma_uint64(inputFrameCount)
 	var cOutputFrameCount C.ma_uint64
 
@@ -103,6 +599,15 @@ func (c *Converter) ExpectOutputFrameCount(inputFrameCount int) (int, error) {
 	return int(cOutputFrameCount), nil
 }
 
+// overflows reports whether scaling frameCount by the ratio of the given sample rates could exceed an int.
+func (c *Converter) overflows(frameCount, rateTo, rateFrom int) bool {
+	if rateFrom <= 0 {
+		return false
+	}
+	factor := rateTo/rateFrom + 1
+	return frameCount > (math.MaxInt-1)/factor
+}
+
 // ProcessFrames processes PCM frames using the data converter.
 //
 // Processing always happens on a per PCM frame basis and always assumes interleaved input and output.
@@ -113,8 +618,94 @@ func (c *Converter) ExpectOutputFrameCount(inputFrameCount int) (int, error) {
 //
 // You can pass in nil for the input buffer in which case it will be treated as an infinitely large
 // buffer of zeros. The output buffer can also be nil, in which case the processing will be treated
-// as seek.
+// as seek. ErrInvalidArgs is returned if a buffer is shorter than its frame count, which is checked in
+// bytes so that FormatS24 frames of odd channel counts are never read or written past the slice.
+//
+// When the sample rates are equal and AllowDynamicSampleRate is not set, the converter has no
+// resampler: every call consumes and writes min(frameCountIn, frameCountOut) frames, with no latency.
+// ProcessFramesNoResample relies on this for a fixed 1:1 conversion.
+//
+// On error, the returned counts are the frames read and written before the error occurred.
 func (c *Converter) ProcessFrames(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, int, error) {
+	var read, written int
+	var err error
+	if c.passthrough {
+		read, err = c.copyFrames(pFramesIn, frameCountIn, pFramesOut, frameCountOut)
+		written = read
+	} else if c.highPrecision != nil {
+		read, written, err = c.resampleHighPrecision(pFramesIn, frameCountIn, pFramesOut, frameCountOut)
+	} else if c.ramp != nil {
+		read, written, err = c.convertRamped(pFramesIn, frameCountIn, pFramesOut, frameCountOut)
+	} else {
+		read, written, err = c.convertFrames(pFramesIn, frameCountIn, pFramesOut, frameCountOut)
+	}
+
+	// A smoothed rate change converts in chunks, so frames may have been processed before an error.
+	c.outputFrameCursor += int64(written)
+	if c.config.OnFrames != nil && len(pFramesOut) > 0 && written > 0 {
+		frameSize := c.outputFrameSize()
+		c.config.OnFrames(pFramesOut[:written*frameSize], written)
+	}
+
+	return read, written, err
+}
+
+// IsPassthrough reports whether the converter leaves frames unchanged, which is the case when the
+// formats, channel counts, channel maps and sample rates match and the sample rate is not dynamic.
+// ProcessFrames then copies the input to the output in Go, without calling into miniaudio.
+func (c *Converter) IsPassthrough() bool {
+	return c.passthrough
+}
+
+// copyFrames is ProcessFrames for a passthrough converter. Like miniaudio, it zeroes the output for a nil input.
+func (c *Converter) copyFrames(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, error) {
+	if frameCountIn < 0 || frameCountOut < 0 {
+		return 0, ErrInvalidArgs
+	}
+	frameCount := frameCountIn
+	if frameCountOut < frameCount {
+		frameCount = frameCountOut
+	}
+	if len(pFramesOut) == 0 {
+		return frameCount, nil
+	}
+
+	size := frameCount * c.outputFrameSize()
+	if len(pFramesOut) < size || (len(pFramesIn) > 0 && len(pFramesIn) < size) {
+		return 0, ErrInvalidArgs
+	}
+	if len(pFramesIn) == 0 {
+		for i := range pFramesOut[:size] {
+			pFramesOut[i] = 0
+		}
+	} else {
+		copy(pFramesOut[:size], pFramesIn)
+	}
+	return frameCount, nil
+}
+
+// validFrameCounts reports whether the buffers hold at least as many frames as their counts.
+func (c *Converter) validFrameCounts(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) bool {
+	return frameCountIn >= 0 && frameCountOut >= 0 &&
+		(len(pFramesIn) == 0 || len(pFramesIn) >= frameCountIn*FrameSizeInBytes(c.config.FormatIn, c.config.ChannelsIn)) &&
+		(len(pFramesOut) == 0 || len(pFramesOut) >= frameCountOut*c.outputFrameSize())
+}
+
+// resampleHighPrecision is ProcessFrames for a HighPrecision converter with different sample rates.
+func (c *Converter) resampleHighPrecision(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, int, error) {
+	if !c.validFrameCounts(pFramesIn, frameCountIn, pFramesOut, frameCountOut) {
+		return 0, 0, ErrInvalidArgs
+	}
+	read, written := c.highPrecision.process(pFramesIn, frameCountIn, pFramesOut, frameCountOut)
+	return read, written, nil
+}
+
+func (c *Converter) convertFrames(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, int, error) {
+	// miniaudio trusts the frame counts, so a buffer shorter than its count would be read or written past its end.
+	if !c.validFrameCounts(pFramesIn, frameCountIn, pFramesOut, frameCountOut) {
+		return 0, 0, ErrInvalidArgs
+	}
+
 	var cFramesIn unsafe.Pointer
 	if len(pFramesIn) == 0 || pFramesIn == nil {
 		cFramesIn = unsafe.Pointer(nil)
@@ -132,10 +723,199 @@ func (c *Converter) ProcessFrames(pFramesIn []byte, frameCountIn int, pFramesOut
 	var cFrameCountIn C.ma_uint64 = C.ma_uint64(frameCountIn)
 	var cFrameCountOut C.ma_uint64 = C.ma_uint64(frameCountOut)
 
-	result := C.ma_data_converter_process_pcm_frames(c.cptr(), cFramesIn, &cFrameCountIn, cFramesOut, &cFrameCountOut)
+	var result C.ma_result
+	if c.config.DitherMode != DitherModeNone {
+		ditherMutex.Lock()
+		if c.isDitherSeeded() {
+			previousState := C.goSwapDitherState(c.ditherState)
+			result = C.ma_data_converter_process_pcm_frames(c.cptr(), cFramesIn, &cFrameCountIn, cFramesOut, &cFrameCountOut)
+			c.ditherState = C.goSwapDitherState(previousState)
+		} else {
+			result = C.ma_data_converter_process_pcm_frames(c.cptr(), cFramesIn, &cFrameCountIn, cFramesOut, &cFrameCountOut)
+		}
+		ditherMutex.Unlock()
+	} else {
+		result = C.ma_data_converter_process_pcm_frames(c.cptr(), cFramesIn, &cFrameCountIn, cFramesOut, &cFrameCountOut)
+	}
 	if result != 0 {
 		return 0, 0, errorFromResult(result)
 	}
 
 	return int(cFrameCountIn), int(cFrameCountOut), nil
 }
+
+// Process converts up to frameCountIn frames from pFramesIn and returns the frames produced along with the
+// number of input frames consumed. The output is sized with ExpectOutputFrameCount, and at least one frame,
+// so fewer frames may be consumed than given when the resampler needs more room; pass the rest to the next call.
+//
+// The returned slice is a view of a buffer owned by the converter that is reused by the next call to Process.
+// Copy or consume the frames before calling Process again. The buffer only grows, so there are no
+// allocations once it fits the largest chunk.
+func (c *Converter) Process(pFramesIn []byte, frameCountIn int) ([]byte, int, error) {
+	if frameCountIn < 0 || len(pFramesIn) < frameCountIn*FrameSizeInBytes(c.config.FormatIn, c.config.ChannelsIn) {
+		return nil, 0, ErrInvalidArgs
+	}
+	frameCountOut, err := c.ExpectOutputFrameCount(frameCountIn)
+	if err != nil {
+		return nil, 0, err
+	}
+	// A few input frames may not complete an output frame when downsampling. An empty output would be
+	// a seek, so leave room for one frame to have the resampler take them in.
+	if frameCountOut == 0 && frameCountIn > 0 {
+		frameCountOut = 1
+	}
+
+	frameSize := c.outputFrameSize()
+	size := frameCountOut * frameSize
+	if cap(c.processBuffer) < size {
+		c.processBuffer = make([]byte, size)
+	}
+	out := c.processBuffer[:size]
+
+	consumed, written, err := c.ProcessFrames(pFramesIn, frameCountIn, out, frameCountOut)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out[:written*frameSize], consumed, nil
+}
+
+// ProcessBytes is like ProcessFrames, but takes input that does not have to end on a frame boundary,
+// such as the bytes of a single Read. It returns the number of bytes of pBytesIn consumed and the
+// number of frames written to pFramesOut.
+//
+// When all whole frames are consumed, the bytes of a trailing partial frame are kept by the converter,
+// counted as consumed, and put in front of the input of the next call. When the output buffer fills up
+// first, the bytes past the last consumed frame are left to the caller to pass again. The kept bytes
+// are discarded by Reinit; PartialFrameBytes returns how many are held.
+func (c *Converter) ProcessBytes(pBytesIn []byte, pFramesOut []byte, frameCountOut int) (int, int, error) {
+	frameSize := FrameSizeInBytes(c.config.FormatIn, c.config.ChannelsIn)
+	if frameSize == 0 {
+		return 0, 0, ErrInvalidArgs
+	}
+
+	data := pBytesIn
+	held := len(c.partialFrame)
+	if held > 0 {
+		c.alignBuffer = append(append(c.alignBuffer[:0], c.partialFrame...), pBytesIn...)
+		data = c.alignBuffer
+	}
+
+	frameCount := len(data) / frameSize
+	read, written, err := c.ProcessFrames(data[:frameCount*frameSize], frameCount, pFramesOut, frameCountOut)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	used := read * frameSize
+	if read == frameCount {
+		c.partialFrame = append(c.partialFrame[:0], data[used:]...)
+		return len(pBytesIn), written, nil
+	}
+	if used < held {
+		return 0, written, nil
+	}
+	c.partialFrame = c.partialFrame[:0]
+	return used - held, written, nil
+}
+
+// LatencyInFrames returns the delay the converter adds to the signal, in output frames. It comes from
+// the resampler's low-pass filter and is zero when the sample rates match and no resampling is done,
+// and with HighPrecision, whose output is time aligned with the input.
+func (c *Converter) LatencyInFrames() int {
+	if c.highPrecision != nil {
+		return 0
+	}
+	return int(C.ma_data_converter_get_output_latency(c.cptr()))
+}
+
+// PartialFrameBytes returns the number of bytes of an incomplete input frame held by ProcessBytes.
+func (c *Converter) PartialFrameBytes() int {
+	return len(c.partialFrame)
+}
+
+// OutputFrameCursor returns the number of frames output by ProcessFrames since the converter was
+// initialized or the cursor was last reset, including frames skipped with a nil output buffer.
+// Divided by the output sample rate it gives the playhead position.
+func (c *Converter) OutputFrameCursor() int64 {
+	return c.outputFrameCursor
+}
+
+// ResetOutputFrameCursor sets the output frame cursor back to zero. Reinit leaves the cursor unchanged.
+func (c *Converter) ResetOutputFrameCursor() {
+	c.outputFrameCursor = 0
+}
+
+// ProcessPCMFrames converts frameCount interleaved frames from in and writes them to out, which lets
+// a converter be used as a Pipeline stage.
+//
+// A stage must output as many frames as it consumes, so ErrInvalidOperation is returned while the
+// input and output sample rates differ. Use ProcessFrames for resampling.
+func (c *Converter) ProcessPCMFrames(out, in []byte, frameCount int) error {
+	sampleRateIn, sampleRateOut := c.sampleRates()
+	if sampleRateIn != sampleRateOut {
+		return ErrInvalidOperation
+	}
+	if frameCount < 0 || len(in) < frameCount*FrameSizeInBytes(c.config.FormatIn, c.config.ChannelsIn) ||
+		len(out) < frameCount*c.outputFrameSize() {
+		return ErrInvalidArgs
+	}
+	if frameCount == 0 {
+		return nil
+	}
+
+	read, written, err := c.ProcessFrames(in, frameCount, out, frameCount)
+	if err != nil {
+		return err
+	}
+	if read != frameCount || written != frameCount {
+		return ErrInvalidOperation
+	}
+	return nil
+}
+
+// ProcessFramesNoResample converts exactly frameCount interleaved frames from in to out, for converters
+// that only change the format, channel count or channel map.
+//
+// ErrInvalidOperation is returned if the converter resamples, which is the case when the sample rates
+// differ or AllowDynamicSampleRate is set. Otherwise the conversion has no latency and output frame i
+// is input frame i converted.
+func (c *Converter) ProcessFramesNoResample(in, out []byte, frameCount int) error {
+	if c.cptr().hasResampler != 0 {
+		return ErrInvalidOperation
+	}
+	return c.ProcessPCMFrames(out, in, frameCount)
+}
+
+func (c *Converter) outputFrameSize() int {
+	return FrameSizeInBytes(c.config.FormatOut, c.config.ChannelsOut)
+}
+
+// ChannelMapIn returns the channel map miniaudio resolved for the input frames.
+func (c *Converter) ChannelMapIn() []Channel {
+	converter := c.cptr()
+	channels := int(converter.channelsIn)
+	channelMap := make([]Channel, channels)
+	if channels == 0 {
+		return channelMap
+	}
+
+	// ma_data_converter_get_input_channel_map returns the output map, so read the channel converter
+	// directly. miniaudio always initializes it, keeping the maps even when it is a passthrough.
+	pChannelMap := (*C.ma_channel)(unsafe.Pointer(&channelMap[0]))
+	C.ma_channel_converter_get_input_channel_map(&converter.channelConverter, pChannelMap, C.size_t(channels))
+	return channelMap
+}
+
+// ChannelMapOut returns the channel map miniaudio resolved for the output frames.
+func (c *Converter) ChannelMapOut() []Channel {
+	converter := c.cptr()
+	channels := int(converter.channelsOut)
+	channelMap := make([]Channel, channels)
+	if channels == 0 {
+		return channelMap
+	}
+
+	pChannelMap := (*C.ma_channel)(unsafe.Pointer(&channelMap[0]))
+	C.ma_channel_converter_get_output_channel_map(&converter.channelConverter, pChannelMap, C.size_t(channels))
+	return channelMap
+}
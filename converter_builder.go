@@ -0,0 +1,74 @@
+package malgo
+
+// ConverterConfigBuilder assembles a ConverterConfig and validates it as a whole.
+//
+// The With methods can be chained and the last call for a setting wins. Using ConverterConfig
+// directly remains equivalent; the builder only moves the validation before InitConverter and
+// describes the problem in the returned error.
+type ConverterConfigBuilder struct {
+	config ConverterConfig
+}
+
+// NewConverterConfigBuilder starts a configuration with the required formats, channel counts and sample rates.
+func NewConverterConfigBuilder(formatIn, formatOut FormatType, channelsIn, channelsOut, rateIn, rateOut int) *ConverterConfigBuilder {
+	return &ConverterConfigBuilder{config: ConverterConfig{
+		FormatIn:      formatIn,
+		FormatOut:     formatOut,
+		ChannelsIn:    channelsIn,
+		ChannelsOut:   channelsOut,
+		SampleRateIn:  rateIn,
+		SampleRateOut: rateOut,
+	}}
+}
+
+// WithResampling sets the resampler configuration.
+func (b *ConverterConfigBuilder) WithResampling(resampling ResampleConfig) *ConverterConfigBuilder {
+	b.config.Resampling = resampling
+	return b
+}
+
+// WithDither sets the dither mode used when converting to a format with fewer bits.
+func (b *ConverterConfigBuilder) WithDither(mode DitherModeType) *ConverterConfigBuilder {
+	b.config.DitherMode = mode
+	return b
+}
+
+// WithChannelMixMode sets how channels are mixed when the channel counts differ.
+func (b *ConverterConfigBuilder) WithChannelMixMode(mode ChannelMixModeType) *ConverterConfigBuilder {
+	b.config.ChannelMixMode = mode
+	return b
+}
+
+// WithChannelMaps sets the input and output channel maps. Either can be nil for the default layout.
+func (b *ConverterConfigBuilder) WithChannelMaps(in, out []Channel) *ConverterConfigBuilder {
+	b.config.ChannelMapIn = in
+	b.config.ChannelMapOut = out
+	return b
+}
+
+// WithWeights sets custom channel weights indexed [in][out]. The mix mode must be set to
+// ChannelMixModeCustomWeights with WithChannelMixMode.
+func (b *ConverterConfigBuilder) WithWeights(weights [][]float32) *ConverterConfigBuilder {
+	b.config.ChannelWeights = weights
+	return b
+}
+
+// Build validates the configuration and returns it. The error wraps ErrInvalidArgs and names the
+// offending setting.
+func (b *ConverterConfigBuilder) Build() (ConverterConfig, error) {
+	config := b.config
+	if SampleSizeInBytes(config.FormatIn) == 0 || SampleSizeInBytes(config.FormatOut) == 0 {
+		return ConverterConfig{}, invalidConfig("input and output formats must be known")
+	}
+	if config.ChannelsIn <= 0 || config.ChannelsOut <= 0 {
+		return ConverterConfig{}, invalidConfig("channel counts must be positive")
+	}
+	if config.SampleRateIn <= 0 || config.SampleRateOut <= 0 {
+		return ConverterConfig{}, invalidConfig("sample rates must be positive")
+	}
+	if err := config.check(); err != nil {
+		return ConverterConfig{}, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,49 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestConverterConfigBuilder(t *testing.T) {
+	// Route the left input channel to the right output channel and the right one to the center.
+	config, err := malgo.NewConverterConfigBuilder(malgo.FormatF32, malgo.FormatF32, 2, 3, 48000, 48000).
+		WithChannelMixMode(malgo.ChannelMixModeCustomWeights).
+		WithWeights([][]float32{{0, 1, 0}, {0, 0, 1}}).
+		WithChannelMaps([]malgo.Channel{malgo.ChannelFrontLeft, malgo.ChannelFrontRight}, nil).
+		Build()
+	assertNil(t, err, "No error expected building config")
+
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 8)
+	binary.LittleEndian.PutUint32(in, math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(in[4:], math.Float32bits(0.25))
+	out := make([]byte, 12)
+	_, _, err = converter.ProcessFrames(in, 1, out, 1)
+	assertNil(t, err, "No error expected processing frames")
+	assertEqual(t, float32(0), math.Float32frombits(binary.LittleEndian.Uint32(out)), "Expected silent left output")
+	assertEqual(t, float32(0.5), math.Float32frombits(binary.LittleEndian.Uint32(out[4:])), "Expected left input on the right")
+	assertEqual(t, float32(0.25), math.Float32frombits(binary.LittleEndian.Uint32(out[8:])), "Expected right input in the center")
+
+	_, err = malgo.NewConverterConfigBuilder(malgo.FormatF32, malgo.FormatF32, 2, 2, 48000, 48000).
+		WithWeights([][]float32{{1, 0}, {0, 1}}).
+		Build()
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for weights without custom mode")
+
+	_, err = malgo.NewConverterConfigBuilder(malgo.FormatF32, malgo.FormatF32, 2, 2, 48000, 48000).
+		WithChannelMixMode(malgo.ChannelMixModeCustomWeights).
+		Build()
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for custom mode without weights")
+
+	_, err = malgo.NewConverterConfigBuilder(malgo.FormatF32, malgo.FormatF32, 2, 6, 48000, 48000).
+		WithChannelMaps(nil, []malgo.Channel{malgo.ChannelFrontLeft, malgo.ChannelFrontRight}).
+		Build()
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for a channel map length mismatch")
+}
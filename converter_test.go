@@ -0,0 +1,708 @@
+package malgo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestConverterReinit(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 64*malgo.FrameSizeInBytes(config.FormatIn, config.ChannelsIn))
+	out := make([]byte, 64*malgo.FrameSizeInBytes(config.FormatOut, config.ChannelsOut))
+	_, written, err := converter.ProcessFrames(in, 64, out, 64)
+	assertNil(t, err, "No error expected processing frames")
+	assertEqual(t, 64, written, "Unexpected output frame count")
+
+	config.ChannelsOut = 6
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing converter")
+
+	out = make([]byte, 64*malgo.FrameSizeInBytes(config.FormatOut, config.ChannelsOut))
+	_, written, err = converter.ProcessFrames(in, 64, out, 64)
+	assertNil(t, err, "No error expected processing frames after reinit")
+	assertEqual(t, 64, written, "Unexpected output frame count after reinit")
+
+	invalid := config
+	invalid.ChannelsOut = 0
+	err = converter.Reinit(invalid)
+	assertNotNil(t, err, "Error expected reinitializing with invalid config")
+
+	_, written, err = converter.ProcessFrames(in, 64, out, 64)
+	assertNil(t, err, "Converter expected to keep previous configuration")
+	assertEqual(t, 64, written, "Unexpected output frame count after failed reinit")
+}
+
+func TestConverterExtremeSampleRateRatio(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  1,
+		SampleRateOut: 192000,
+	}
+	_, err := malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected upsampling ratio to be rejected")
+
+	config.SampleRateIn, config.SampleRateOut = config.SampleRateOut, config.SampleRateIn
+	_, err = malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected downsampling ratio to be rejected")
+
+	config.SampleRateIn, config.SampleRateOut = 48, 48000
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected at the maximum ratio")
+	converter.Uninit()
+
+	config.SampleRateIn, config.SampleRateOut = 1, 192000
+	config.MaxSampleRateRatio = 192000
+	converter, err = malgo.InitConverter(config)
+	assertNil(t, err, "No error expected with a raised maximum ratio")
+	defer converter.Uninit()
+
+	_, err = converter.ExpectOutputFrameCount(1 << 60)
+	assertEqual(t, malgo.ErrTooBig, err, "Expected overflowing output frame count to be rejected")
+
+	_, err = converter.ExpectOutputFrameCount(-1)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected negative input frame count to be rejected")
+
+	count, err := converter.ExpectOutputFrameCount(10)
+	assertNil(t, err, "No error expected for a small input frame count")
+	assertTrue(t, count > 0, "Expected output frames")
+}
+
+func TestConverterWithoutLowPassFilter(t *testing.T) {
+	downsample := func(lpfOrder uint32) float64 {
+		config := malgo.ConverterConfig{
+			FormatIn:      malgo.FormatF32,
+			FormatOut:     malgo.FormatF32,
+			ChannelsIn:    1,
+			ChannelsOut:   1,
+			SampleRateIn:  48000,
+			SampleRateOut: 16000,
+			Resampling: malgo.ResampleConfig{
+				Algorithm: malgo.ResampleAlgorithmLinear,
+				Linear:    malgo.ResampleLinearConfig{LpfOrder: lpfOrder},
+			},
+		}
+		converter, err := malgo.InitConverter(config)
+		assertNil(t, err, "No error expected initializing converter")
+		defer converter.Uninit()
+
+		// A 12 kHz tone is above the output Nyquist frequency and only survives through aliasing.
+		const frameCount = 48000
+		in := make([]byte, frameCount*4)
+		for i := 0; i < frameCount; i++ {
+			sample := float32(math.Sin(2 * math.Pi * 12000 * float64(i) / 48000))
+			binary.LittleEndian.PutUint32(in[i*4:], math.Float32bits(sample))
+		}
+		out := make([]byte, frameCount*4)
+		_, written, err := converter.ProcessFrames(in, frameCount, out, frameCount)
+		assertNil(t, err, "No error expected processing frames")
+
+		sum := 0.0
+		for i := 0; i < written; i++ {
+			sample := float64(math.Float32frombits(binary.LittleEndian.Uint32(out[i*4:])))
+			sum += sample * sample
+		}
+		return math.Sqrt(sum / float64(written))
+	}
+
+	unfiltered := downsample(0)
+	filtered := downsample(8)
+	if unfiltered < 4*filtered {
+		t.Fatalf("expected aliasing without low-pass filter, got RMS %f unfiltered vs %f filtered", unfiltered, filtered)
+	}
+}
+
+func TestDefaultLpfOrder(t *testing.T) {
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	assertEqual(t, uint32(malgo.DefaultLpfOrder), deviceConfig.Resampling.Linear.LpfOrder, "Expected miniaudio's default order in the device config")
+}
+
+func TestConverterOnFrames(t *testing.T) {
+	var calls, frames, size int
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   1,
+		SampleRateIn:  44100,
+		SampleRateOut: 44100,
+		OnFrames: func(out []byte, frameCount int) {
+			calls++
+			frames += frameCount
+			size += len(out)
+		},
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 100*malgo.FrameSizeInBytes(config.FormatIn, config.ChannelsIn))
+	out := make([]byte, 200*malgo.FrameSizeInBytes(config.FormatOut, config.ChannelsOut))
+	_, written, err := converter.ProcessFrames(in, 100, out, 200)
+	assertNil(t, err, "No error expected processing frames")
+
+	assertEqual(t, 1, calls, "Expected one callback per ProcessFrames call")
+	assertEqual(t, written, frames, "Expected callback frame count to match output")
+	assertEqual(t, written*4, size, "Expected callback slice to cover exactly the written frames")
+}
+
+func TestConverterDitherSeed(t *testing.T) {
+	const frameCount = 1024
+	in := make([]byte, frameCount*4)
+	for i := 0; i < frameCount; i++ {
+		sample := float32(0.001 * math.Sin(2*math.Pi*440*float64(i)/48000))
+		binary.LittleEndian.PutUint32(in[i*4:], math.Float32bits(sample))
+	}
+
+	convertSeeded := func(seed uint32) ([]byte, error) {
+		converter, err := malgo.InitConverter(malgo.ConverterConfig{
+			FormatIn:      malgo.FormatF32,
+			FormatOut:     malgo.FormatS16,
+			ChannelsIn:    1,
+			ChannelsOut:   1,
+			SampleRateIn:  48000,
+			SampleRateOut: 48000,
+			DitherMode:    malgo.DitherModeTriangle,
+			DitherSeed:    seed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer converter.Uninit()
+
+		out := make([]byte, frameCount*2)
+		_, _, err = converter.ProcessFrames(in, frameCount, out, frameCount)
+		return out, err
+	}
+	convert := func(seed uint32) []byte {
+		out, err := convertSeeded(seed)
+		assertNil(t, err, "No error expected converting frames")
+		return out
+	}
+
+	first := convert(42)
+	convert(0)
+	second := convert(42)
+	assertTrue(t, bytes.Equal(first, second), "Expected identical output with the same dither seed")
+
+	// Unseeded converters dithering concurrently must not disturb the seeded sequence.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			_, _ = convertSeeded(0)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		assertTrue(t, bytes.Equal(first, convert(42)), "Expected identical output while other converters dither")
+	}
+	<-done
+	assertTrue(t, !bytes.Equal(first, convert(43)), "Expected different output with a different dither seed")
+}
+
+func TestConverterRateRatio(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:               malgo.FormatF32,
+		FormatOut:              malgo.FormatF32,
+		ChannelsIn:             2,
+		ChannelsOut:            2,
+		SampleRateIn:           44100,
+		SampleRateOut:          48000,
+		AllowDynamicSampleRate: true,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	assertEqual(t, 44100.0/48000.0, converter.RateRatio(), "Unexpected initial ratio")
+
+	err = converter.SetRate(48000, 24000)
+	assertNil(t, err, "No error expected setting rate")
+	assertEqual(t, 2.0, converter.RateRatio(), "Unexpected ratio after SetRate")
+
+	err = converter.SetRateRatio(0.5)
+	assertNil(t, err, "No error expected setting rate ratio")
+	assertEqual(t, 0.5, converter.RateRatio(), "Unexpected ratio after SetRateRatio")
+
+	err = converter.SetRateRatio(0)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected zero ratio to be rejected")
+}
+
+func TestConverterMaxChannels(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    16,
+		ChannelsOut:   16,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	}
+
+	// Third order ambisonics.
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing a 16 channel converter")
+	converter.Uninit()
+
+	config.ChannelsOut = malgo.MaxChannels + 1
+	_, err = malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for too many channels")
+	assertTrue(t, strings.Contains(err.Error(), "MaxChannels"), "Expected the error to name MaxChannels")
+
+	config.ChannelsOut = 2
+	config.ChannelMapOut = []malgo.Channel{malgo.ChannelMono}
+	_, err = malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for a channel map length mismatch")
+	assertTrue(t, strings.Contains(err.Error(), "ChannelMapOut"), "Expected the error to name the channel map")
+}
+
+func TestConverterChannelMaps(t *testing.T) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    6,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	expectedIn := []malgo.Channel{malgo.ChannelFrontLeft, malgo.ChannelFrontRight, malgo.ChannelFrontCenter,
+		malgo.ChannelLFE, malgo.ChannelSideLeft, malgo.ChannelSideRight}
+	mapIn := converter.ChannelMapIn()
+	assertEqual(t, len(expectedIn), len(mapIn), "Unexpected input channel count")
+	for i := range expectedIn {
+		assertEqual(t, expectedIn[i], mapIn[i], "Unexpected input channel position")
+	}
+
+	mapOut := converter.ChannelMapOut()
+	assertEqual(t, 2, len(mapOut), "Unexpected output channel count")
+	assertEqual(t, malgo.ChannelLeft, mapOut[0], "Unexpected output channel position")
+	assertEqual(t, malgo.ChannelRight, mapOut[1], "Unexpected output channel position")
+}
+
+func TestConverterPassthroughChannelMaps(t *testing.T) {
+	swapped := []malgo.Channel{malgo.ChannelFrontRight, malgo.ChannelFrontLeft}
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+		ChannelMapIn:  swapped,
+		ChannelMapOut: swapped,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	for _, channelMap := range [][]malgo.Channel{converter.ChannelMapIn(), converter.ChannelMapOut()} {
+		assertEqual(t, 2, len(channelMap), "Unexpected channel count")
+		assertEqual(t, swapped[0], channelMap[0], "Expected the configured map")
+		assertEqual(t, swapped[1], channelMap[1], "Expected the configured map")
+	}
+}
+
+func TestConverterOutputFrameCursor(t *testing.T) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  24000,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 100*2)
+	out := make([]byte, 400*2)
+	total := 0
+	for i := 0; i < 3; i++ {
+		_, written, err := converter.ProcessFrames(in, 100, out, 400)
+		assertNil(t, err, "No error expected processing frames")
+		total += written
+	}
+	assertEqual(t, int64(total), converter.OutputFrameCursor(), "Expected cursor to count output frames")
+
+	converter.ResetOutputFrameCursor()
+	assertEqual(t, int64(0), converter.OutputFrameCursor(), "Expected cursor to be reset")
+
+	_, written, err := converter.ProcessFrames(in, 100, nil, 50)
+	assertNil(t, err, "No error expected seeking")
+	assertEqual(t, int64(written), converter.OutputFrameCursor(), "Expected seeking to advance the cursor")
+}
+
+func TestConverterProcess(t *testing.T) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  44100,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 441*4)
+	out, consumed, err := converter.Process(in, 441)
+	assertNil(t, err, "No error expected processing frames")
+	assertTrue(t, consumed > 0 && consumed <= 441, "Expected input frames to be consumed")
+	assertTrue(t, len(out) > 0 && len(out)%8 == 0, "Expected whole output frames")
+
+	second, _, err := converter.Process(in[:100*4], 100)
+	assertNil(t, err, "No error expected processing frames")
+	assertTrue(t, &second[0] == &out[0], "Expected the output buffer to be reused")
+
+	_, _, err = converter.Process(in, 442)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short input buffer")
+}
+
+func TestConverterProcessBytes(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	in := make([]byte, 10*4)
+	for i := range in {
+		in[i] = byte(i)
+	}
+	out := make([]byte, 10*4)
+
+	consumed, written, err := converter.ProcessBytes(in[:7], out, 10)
+	assertNil(t, err, "No error expected processing bytes")
+	assertEqual(t, 7, consumed, "Expected the partial frame to be consumed")
+	assertEqual(t, 1, written, "Expected one whole frame")
+	assertEqual(t, 3, converter.PartialFrameBytes(), "Expected the partial frame to be held")
+
+	consumed, written, err = converter.ProcessBytes(in[7:], out[4:], 9)
+	assertNil(t, err, "No error expected processing bytes")
+	assertEqual(t, 33, consumed, "Expected all bytes to be consumed")
+	assertEqual(t, 9, written, "Expected the held frame to be completed")
+	assertEqual(t, 0, converter.PartialFrameBytes(), "Expected no partial frame")
+	assertTrue(t, bytes.Equal(in, out), "Expected the frames to pass through unchanged")
+
+	consumed, written, err = converter.ProcessBytes(in[:6], out, 10)
+	assertNil(t, err, "No error expected processing bytes")
+	assertEqual(t, 6, consumed, "Expected all bytes to be consumed")
+	assertEqual(t, 1, written, "Expected one whole frame")
+
+	consumed, written, err = converter.ProcessBytes(in[6:], out, 2)
+	assertNil(t, err, "No error expected processing bytes")
+	assertEqual(t, 6, consumed, "Expected only the bytes of the written frames to be consumed")
+	assertEqual(t, 2, written, "Expected the output to be full")
+	assertEqual(t, 0, converter.PartialFrameBytes(), "Expected the held bytes to be used")
+
+	_, _, err = converter.ProcessBytes(in[:3], out, 10)
+	assertNil(t, err, "No error expected processing bytes")
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing")
+	assertEqual(t, 0, converter.PartialFrameBytes(), "Expected Reinit to discard the partial frame")
+}
+
+func TestConverterPassthrough(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+	assertTrue(t, converter.IsPassthrough(), "Expected a passthrough converter")
+
+	in := make([]byte, 8*4)
+	for i := range in {
+		in[i] = byte(i + 1)
+	}
+	out := make([]byte, 8*4)
+	read, written, err := converter.ProcessFrames(in, 8, out, 6)
+	assertNil(t, err, "No error expected processing frames")
+	assertEqual(t, 6, read, "Expected input to be limited by the output")
+	assertEqual(t, 6, written, "Expected output to be limited by its size")
+	assertTrue(t, bytes.Equal(in[:6*4], out[:6*4]), "Expected frames to be copied")
+	assertEqual(t, int64(6), converter.OutputFrameCursor(), "Expected the cursor to advance")
+
+	_, written, err = converter.ProcessFrames(nil, 8, out, 8)
+	assertNil(t, err, "No error expected processing a nil input")
+	assertEqual(t, 8, written, "Expected silence to be written")
+	assertTrue(t, bytes.Equal(make([]byte, len(out)), out), "Expected zeroed output")
+
+	_, _, err = converter.ProcessFrames(in, 8, out[:4], 8)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short output buffer")
+
+	config.ChannelsOut = 1
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing")
+	assertTrue(t, !converter.IsPassthrough(), "Expected a channel conversion not to be passthrough")
+
+	config.ChannelsOut = 2
+	config.AllowDynamicSampleRate = true
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing")
+	assertTrue(t, !converter.IsPassthrough(), "Expected a dynamic sample rate not to be passthrough")
+}
+
+func BenchmarkConverterPassthrough(b *testing.B) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer converter.Uninit()
+
+	in := make([]byte, 480*8)
+	out := make([]byte, 480*8)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		_, _, _ = converter.ProcessFrames(in, 480, out, 480)
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	in := make([]byte, 480*8)
+	out := make([]byte, 480*8)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		copy(out, in)
+	}
+}
+
+func TestConverterSetDitherMode(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+		DitherSeed:    1,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+	assertEqual(t, malgo.DitherModeNone, converter.DitherMode(), "Expected no dither initially")
+
+	in := sineF32(440, 0.001, 48000, 256, 1)
+	convert := func() []byte {
+		out := make([]byte, 256*2)
+		_, _, err := converter.ProcessFrames(in, 256, out, 256)
+		assertNil(t, err, "No error expected processing frames")
+		return out
+	}
+
+	plain := convert()
+	err = converter.SetDitherMode(malgo.DitherModeTriangle)
+	assertNil(t, err, "No error expected setting the dither mode")
+	assertEqual(t, malgo.DitherModeTriangle, converter.DitherMode(), "Expected the new dither mode")
+	assertTrue(t, !bytes.Equal(plain, convert()), "Expected dither to change the output")
+
+	err = converter.SetDitherMode(malgo.DitherModeNone)
+	assertNil(t, err, "No error expected setting the dither mode")
+	assertTrue(t, bytes.Equal(plain, convert()), "Expected the undithered output again")
+
+	err = converter.SetDitherMode(malgo.DitherModeTriangle + 1)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for an unknown dither mode")
+}
+
+func TestConverterSetDitherModeHighPrecision(t *testing.T) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  44100,
+		SampleRateOut: 48000,
+		HighPrecision: true,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	err = converter.SetDitherMode(malgo.DitherModeTriangle)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error enabling dither on a HighPrecision converter")
+	assertEqual(t, malgo.DitherModeNone, converter.DitherMode(), "Expected the dither mode to be unchanged")
+
+	err = converter.SetDitherMode(malgo.DitherModeNone)
+	assertNil(t, err, "No error expected keeping dither disabled")
+}
+
+func TestConverterRateChangeSmoothing(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:               malgo.FormatF32,
+		FormatOut:              malgo.FormatF32,
+		ChannelsIn:             1,
+		ChannelsOut:            1,
+		SampleRateIn:           48000,
+		SampleRateOut:          48000,
+		AllowDynamicSampleRate: true,
+		RateChangeSmoothFrames: 4800,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	err = converter.SetRate(48000, 24000)
+	assertNil(t, err, "No error expected setting rate")
+	assertEqual(t, 1.0, converter.RateRatio(), "Expected the ratio to change while processing")
+
+	in := make([]byte, 480*4)
+	out := make([]byte, 480*4)
+	read, written, err := converter.ProcessFrames(in, 480, out, 480)
+	assertNil(t, err, "No error expected processing frames")
+	assertEqual(t, 480, read, "Expected all input to be consumed")
+	assertTrue(t, written > 400, "Expected close to the initial ratio at the start of the ramp")
+	ratio := converter.RateRatio()
+	assertTrue(t, ratio > 1 && ratio < 1.2, "Expected an intermediate ratio")
+
+	for i := 0; i < 10; i++ {
+		_, _, err = converter.ProcessFrames(in, 480, out, 480)
+		assertNil(t, err, "No error expected processing frames")
+	}
+	assertEqual(t, 2.0, converter.RateRatio(), "Expected the target ratio at the end of the ramp")
+
+	_, written, err = converter.ProcessFrames(in, 480, out, 480)
+	assertNil(t, err, "No error expected processing frames")
+	assertTrue(t, written >= 239 && written <= 241, "Expected the new ratio after the ramp")
+
+	config.RateChangeSmoothFrames = -1
+	_, err = malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for a negative smoothing length")
+}
+
+func TestConverterProcessFramesNoResample(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+	assertEqual(t, 0, converter.LatencyInFrames(), "Expected no latency without resampling")
+
+	const frameCount = 5
+	in := make([]byte, frameCount*2*2)
+	for i := 0; i < frameCount*2; i++ {
+		binary.LittleEndian.PutUint16(in[i*2:], uint16(int16(i*1000)))
+	}
+	out := make([]byte, frameCount*2*4)
+	err = converter.ProcessFramesNoResample(in, out, frameCount)
+	assertNil(t, err, "No error expected converting frames")
+	for i := 0; i < frameCount*2; i++ {
+		sample := math.Float32frombits(binary.LittleEndian.Uint32(out[i*4:]))
+		assertEqual(t, float32(i*1000)/32768, sample, "Expected each sample to map to the same position")
+	}
+
+	err = converter.ProcessFramesNoResample(in, out[:4], frameCount)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short output buffer")
+
+	config.SampleRateOut = 44100
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing")
+	err = converter.ProcessFramesNoResample(in, out, frameCount)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error for differing sample rates")
+
+	config.SampleRateOut = 48000
+	config.AllowDynamicSampleRate = true
+	err = converter.Reinit(config)
+	assertNil(t, err, "No error expected reinitializing")
+	err = converter.ProcessFramesNoResample(in, out, frameCount)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error for a dynamic sample rate")
+}
+
+func TestConverterS24OddChannels(t *testing.T) {
+	for _, channels := range []int{3, 5} {
+		converter, err := malgo.InitConverter(malgo.ConverterConfig{
+			FormatIn:      malgo.FormatS24,
+			FormatOut:     malgo.FormatS16,
+			ChannelsIn:    channels,
+			ChannelsOut:   channels,
+			SampleRateIn:  48000,
+			SampleRateOut: 48000,
+		})
+		assertNil(t, err, "No error expected initializing converter")
+
+		const frameCount = 4
+		in := make([]byte, frameCount*channels*3)
+		out := make([]byte, frameCount*channels*2)
+
+		// One byte short of the last frame, as if sized for 2 bytes less per frame.
+		_, _, err = converter.ProcessFrames(in[:len(in)-1], frameCount, out, frameCount)
+		assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short S24 input")
+		_, _, err = converter.ProcessFrames(in, frameCount, out[:len(out)-1], frameCount)
+		assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short output")
+
+		read, written, err := converter.ProcessFrames(in, frameCount, out, frameCount)
+		assertNil(t, err, "No error expected processing whole frames")
+		assertEqual(t, frameCount, read, "Expected all input to be consumed")
+		assertEqual(t, frameCount, written, "Expected all frames to be written")
+		converter.Uninit()
+	}
+}
+
+func TestConverterRateChangeSmoothingPartialError(t *testing.T) {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:               malgo.FormatF32,
+		FormatOut:              malgo.FormatF32,
+		ChannelsIn:             1,
+		ChannelsOut:            1,
+		SampleRateIn:           48000,
+		SampleRateOut:          48000,
+		AllowDynamicSampleRate: true,
+		RateChangeSmoothFrames: 4800,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	err = converter.SetRate(48000, 24000)
+	assertNil(t, err, "No error expected setting rate")
+
+	// The input holds 40 frames but claims 64, so the second chunk of the ramp fails.
+	in := make([]byte, 40*4)
+	out := make([]byte, 128*4)
+	read, written, err := converter.ProcessFrames(in, 64, out, 128)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short input")
+	assertEqual(t, 32, read, "Expected the frames of the first chunk to be reported")
+	assertTrue(t, written > 0, "Expected the output of the first chunk to be reported")
+	assertEqual(t, int64(written), converter.OutputFrameCursor(), "Expected the cursor to advance by the frames written")
+}
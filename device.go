@@ -3,13 +3,25 @@ package malgo
 // #include "malgo.h"
 import "C"
 import (
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 // DataProc type.
 type DataProc func(pOutputSample, pInputSamples []byte, framecount uint32)
 
+// DataProcF32 type.
+type DataProcF32 func(pOutputSamples, pInputSamples []float32, frameCount int)
+
+// DataProcS16 type.
+type DataProcS16 func(pOutputSamples, pInputSamples []int16, frameCount int)
+
 // StopProc type.
 type StopProc func()
 
@@ -17,13 +29,175 @@ type StopProc func()
 type DeviceCallbacks struct {
 	// Data is called for the full duplex IO.
 	Data DataProc
+	// DataF32 is called instead of Data for devices using FormatF32.
+	DataF32 DataProcF32
+	// DataS16 is called instead of Data for devices using FormatS16.
+	DataS16 DataProcS16
 	// Stop is called when the device stopped.
 	Stop StopProc
+	// Notification is called when the device is started, stopped, rerouted or interrupted.
+	Notification NotificationProc
+}
+
+// dataProc returns the registered data callback, adapting a typed one to DataProc.
+// An error is returned if more than one data callback is set.
+func (callbacks DeviceCallbacks) dataProc() (DataProc, FormatType, error) {
+	count := 0
+	for _, set := range []bool{callbacks.Data != nil, callbacks.DataF32 != nil, callbacks.DataS16 != nil} {
+		if set {
+			count++
+		}
+	}
+	if count > 1 {
+		return nil, FormatUnknown, ErrInvalidArgs
+	}
+
+	switch {
+	case callbacks.DataF32 != nil:
+		proc := callbacks.DataF32
+		return func(pOutputSamples, pInputSamples []byte, framecount uint32) {
+			proc(bytesAsFloat32(pOutputSamples), bytesAsFloat32(pInputSamples), int(framecount))
+		}, FormatF32, nil
+	case callbacks.DataS16 != nil:
+		proc := callbacks.DataS16
+		return func(pOutputSamples, pInputSamples []byte, framecount uint32) {
+			proc(bytesAsInt16(pOutputSamples), bytesAsInt16(pInputSamples), int(framecount))
+		}, FormatS16, nil
+	}
+
+	return callbacks.Data, FormatUnknown, nil
+}
+
+// selectCaptureChannels wraps a data callback so that it only receives the given capture channels.
+func selectCaptureChannels(proc DataProc, format FormatType, channels int, indices []int) (DataProc, error) {
+	for _, index := range indices {
+		if index < 0 || index >= channels {
+			return nil, ErrInvalidArgs
+		}
+	}
+	indices = append([]int(nil), indices...)
+	sampleSize := SampleSizeInBytes(format)
+
+	var selected []byte
+	return func(pOutputSample, pInputSamples []byte, framecount uint32) {
+		if pInputSamples != nil {
+			size := int(framecount) * len(indices) * sampleSize
+			if cap(selected) < size {
+				selected = make([]byte, size)
+			}
+			selected = selected[:size]
+
+			for frame := 0; frame < int(framecount); frame++ {
+				for i, index := range indices {
+					src := (frame*channels + index) * sampleSize
+					dst := (frame*len(indices) + i) * sampleSize
+					copy(selected[dst:dst+sampleSize], pInputSamples[src:src+sampleSize])
+				}
+			}
+			pInputSamples = selected
+		}
+		proc(pOutputSample, pInputSamples, framecount)
+	}, nil
+}
+
+// applyBalance wraps a data callback so that the stereo playback output it writes is balanced by
+// the pan stored in the bits of pan.
+func applyBalance(proc DataProc, format FormatType, pan *uint32) DataProc {
+	return func(pOutputSample, pInputSamples []byte, framecount uint32) {
+		proc(pOutputSample, pInputSamples, framecount)
+
+		value := math.Float32frombits(atomic.LoadUint32(pan))
+		if value == 0 || pOutputSample == nil {
+			return
+		}
+		gains := [2]float64{1, 1}
+		if value > 0 {
+			gains[0] = 1 - float64(value)
+		} else {
+			gains[1] = 1 + float64(value)
+		}
+		for i := 0; i < int(framecount)*2; i++ {
+			setSampleAt(format, pOutputSample, i, sampleAt(format, pOutputSample, i)*gains[i%2])
+		}
+	}
+}
+
+// deviceFade ramps the playback output in after Start and out before Stop.
+type deviceFade struct {
+	startStep float64 // Gain change per frame while fading in; 0 for no fade.
+	stopStep  float64 // Gain change per frame while fading out; 0 for no fade.
+	gain      uint64  // Current gain as float64 bits, updated by the audio thread.
+	fadingOut uint32
+	faded     chan struct{}
+}
+
+func newDeviceFade(config DeviceConfig, sampleRate uint32) *deviceFade {
+	if config.StartFadeMilliseconds == 0 && config.StopFadeMilliseconds == 0 {
+		return nil
+	}
+
+	fade := &deviceFade{faded: make(chan struct{}, 1)}
+	if config.StartFadeMilliseconds > 0 {
+		fade.startStep = 1000 / (float64(config.StartFadeMilliseconds) * float64(sampleRate))
+	}
+	if config.StopFadeMilliseconds > 0 {
+		fade.stopStep = 1000 / (float64(config.StopFadeMilliseconds) * float64(sampleRate))
+	}
+	fade.reset()
+	return fade
+}
+
+// reset prepares the fade in. It must be called while the audio thread is not running.
+func (f *deviceFade) reset() {
+	gain := 1.0
+	if f.startStep > 0 {
+		gain = 0
+	}
+	atomic.StoreUint64(&f.gain, math.Float64bits(gain))
+	atomic.StoreUint32(&f.fadingOut, 0)
+	select {
+	case <-f.faded:
+	default:
+	}
+}
+
+func applyFade(proc DataProc, format FormatType, channels int, fade *deviceFade) DataProc {
+	return func(pOutputSample, pInputSamples []byte, framecount uint32) {
+		proc(pOutputSample, pInputSamples, framecount)
+
+		gain := math.Float64frombits(atomic.LoadUint64(&fade.gain))
+		fadingOut := atomic.LoadUint32(&fade.fadingOut) != 0
+		if gain == 1 && !fadingOut || pOutputSample == nil {
+			return
+		}
+		for frame := 0; frame < int(framecount); frame++ {
+			if fadingOut {
+				gain = math.Max(gain-fade.stopStep, 0)
+			} else {
+				gain = math.Min(gain+fade.startStep, 1)
+			}
+			for i := frame * channels; i < (frame+1)*channels; i++ {
+				setSampleAt(format, pOutputSample, i, sampleAt(format, pOutputSample, i)*gain)
+			}
+		}
+		atomic.StoreUint64(&fade.gain, math.Float64bits(gain))
+
+		if fadingOut && gain == 0 {
+			select {
+			case fade.faded <- struct{}{}:
+			default:
+			}
+		}
+	}
 }
 
 // Device represents a streaming instance.
 type Device struct {
-	ptr *unsafe.Pointer
+	ptr    *unsafe.Pointer
+	config DeviceConfig
+	pan    *uint32
+	fade   *deviceFade
+	dead   bool // Set when SwitchDevice could neither open the new device nor reopen the previous one.
 }
 
 // InitDevice initializes a device.
@@ -34,11 +208,29 @@ type Device struct {
 // Set device ID to nil to use the default device. Do _not_ rely on the first device ID returned
 // by Context.Devices() to be the default device.
 //
+// When a typed data callback (DataF32, DataS16) is used, the negotiated device format must match it,
+// otherwise ErrFormatNotSupported is returned.
+//
 // The returned instance has to be cleaned up using Uninit().
 func InitDevice(context Context, deviceConfig DeviceConfig, deviceCallbacks DeviceCallbacks) (*Device, error) {
+	dataProc, dataFormat, err := deviceCallbacks.dataProc()
+	if err != nil {
+		return nil, err
+	}
+	if deviceConfig.Playback.ChannelMixMode == ChannelMixModeCustomWeights ||
+		deviceConfig.Capture.ChannelMixMode == ChannelMixModeCustomWeights {
+		return nil, ErrInvalidArgs
+	}
+
+	if err := useSystemDefaultFormats(context, &deviceConfig); err != nil {
+		return nil, err
+	}
+
 	ptr := C.ma_malloc(C.sizeof_ma_device, nil)
 	dev := Device{
-		ptr: &ptr,
+		ptr:    &ptr,
+		config: deviceConfig,
+		pan:    new(uint32),
 	}
 	if uintptr(*dev.ptr) == 0 {
 		return nil, ErrOutOfMemory
@@ -48,19 +240,135 @@ func InitDevice(context Context, deviceConfig DeviceConfig, deviceCallbacks Devi
 
 	rawDevice := dev.cptr()
 	C.goSetDeviceConfigCallbacks(&devConfigC)
+	if deviceCallbacks.Notification != nil {
+		C.goSetDeviceConfigNotificationCallback(&devConfigC)
+	}
 	result := C.ma_device_init(context.cptr(), &devConfigC, rawDevice)
 	if result != 0 {
 		dev.free()
 		return nil, errorFromResult(result)
 	}
+	if dataFormat != FormatUnknown && !dev.usesFormat(dataFormat) {
+		C.ma_device_uninit(rawDevice)
+		dev.free()
+		return nil, ErrFormatNotSupported
+	}
+	if len(deviceConfig.Capture.ChannelIndices) > 0 && dataProc != nil {
+		dataProc, err = selectCaptureChannels(dataProc, dev.CaptureFormat(), int(dev.CaptureChannels()), deviceConfig.Capture.ChannelIndices)
+		if err != nil {
+			C.ma_device_uninit(rawDevice)
+			dev.free()
+			return nil, err
+		}
+	}
+	if dev.Type() != Capture && dev.Type() != Loopback && dev.PlaybackChannels() == 2 && dataProc != nil {
+		dataProc = applyBalance(dataProc, dev.PlaybackFormat(), dev.pan)
+	}
+	if dev.Type() != Capture && dev.Type() != Loopback && dataProc != nil {
+		dev.fade = newDeviceFade(deviceConfig, dev.SampleRate())
+		if dev.fade != nil {
+			dataProc = applyFade(dataProc, dev.PlaybackFormat(), int(dev.PlaybackChannels()), dev.fade)
+		}
+	}
 	deviceMutex.Lock()
-	dataCallbacks[rawDevice] = deviceCallbacks.Data
+	dataCallbacks[rawDevice] = dataProc
 	stopCallbacks[rawDevice] = deviceCallbacks.Stop
+	if deviceCallbacks.Notification != nil {
+		notificationCallbacks[rawDevice] = deviceCallbacks.Notification
+		deviceFormats[rawDevice] = deviceNativeFormats(rawDevice)
+	}
 	deviceMutex.Unlock()
 
 	return &dev, nil
 }
 
+// useSystemDefaultFormats applies the first reported native format of the devices whose sub-config sets
+// UseSystemDefaultFormat.
+func useSystemDefaultFormats(context Context, config *DeviceConfig) error {
+	if config.DeviceType != Capture && config.Playback.UseSystemDefaultFormat {
+		if err := config.Playback.useSystemDefaultFormat(context, Playback, &config.SampleRate); err != nil {
+			return err
+		}
+	}
+	if config.DeviceType != Playback && config.Capture.UseSystemDefaultFormat {
+		kind := Capture
+		if config.DeviceType == Loopback {
+			kind = Playback
+		}
+		sampleRate := &config.SampleRate
+		if config.DeviceType == Duplex && config.Playback.UseSystemDefaultFormat {
+			sampleRate = new(uint32)
+		}
+		if err := config.Capture.useSystemDefaultFormat(context, kind, sampleRate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sub *SubConfig) useSystemDefaultFormat(context Context, kind DeviceType, sampleRate *uint32) error {
+	var info C.ma_device_info
+	result := C.ma_context_get_device_info(context.cptr(), C.ma_device_type(kind), (*C.ma_device_id)(sub.DeviceID), &info)
+	if err := errorFromResult(result); err != nil {
+		return err
+	}
+	if info.nativeDataFormatCount == 0 {
+		return nil
+	}
+
+	// Backends do not mark a default among the native formats, so the first reported one is used.
+	sub.applyNativeFormat(deviceInfoFromPointer(unsafe.Pointer(&info)).Formats[0], sampleRate)
+	return nil
+}
+
+// applyNativeFormat overrides the sample format, channel count and sample rate with the values the
+// native format sets, and keeps the requested values where the native format leaves them zero.
+func (sub *SubConfig) applyNativeFormat(native DataFormat, sampleRate *uint32) {
+	if native.Format != FormatUnknown {
+		sub.Format = native.Format
+	}
+	if native.Channels != 0 {
+		sub.Channels = native.Channels
+	}
+	if native.SampleRate != 0 {
+		*sampleRate = native.SampleRate
+	}
+}
+
+// InitDeviceWithFallback tries to initialize a device with each of the configs in order, and returns
+// the first device that could be initialized together with the config that was used.
+//
+// If none succeeds, the returned error is a *FallbackError holding the error of every attempt.
+func InitDeviceWithFallback(context Context, configs []DeviceConfig, deviceCallbacks DeviceCallbacks) (*Device, DeviceConfig, error) {
+	if len(configs) == 0 {
+		return nil, DeviceConfig{}, ErrInvalidArgs
+	}
+
+	errs := make([]error, 0, len(configs))
+	for _, config := range configs {
+		dev, err := InitDevice(context, config, deviceCallbacks)
+		if err == nil {
+			return dev, config, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, DeviceConfig{}, &FallbackError{Errors: errs}
+}
+
+// FallbackError lists the errors of InitDeviceWithFallback, one per config in the order they were tried.
+type FallbackError struct {
+	Errors []error
+}
+
+func (e *FallbackError) Error() string {
+	var b strings.Builder
+	b.WriteString("malgo: no device config succeeded")
+	for i, err := range e.Errors {
+		fmt.Fprintf(&b, "; config %d: %v", i, err)
+	}
+	return b.String()
+}
+
 func (dev Device) cptr() *C.ma_device {
 	return (*C.ma_device)(*dev.ptr)
 }
@@ -71,6 +379,59 @@ func (dev Device) free() {
 	}
 }
 
+// usesFormat reports whether every active direction of the device uses the given format.
+func (dev *Device) usesFormat(format FormatType) bool {
+	kind := dev.Type()
+	if kind != Capture && kind != Loopback && dev.PlaybackFormat() != format {
+		return false
+	}
+	if kind != Playback && dev.CaptureFormat() != format {
+		return false
+	}
+	return true
+}
+
+// CallbackBufferInfo describes the buffers passed to the data callback.
+//
+// With fixed sized callbacks, the default unless NoFixedSizedCallback is set, miniaudio calls back with
+// its own buffers of sizeFrames frames every time, and alignment is the largest power of two, up to 64,
+// that their addresses are a multiple of. Otherwise the buffers come from the backend or the converter
+// with varying sizes and no alignment guarantee, which is reported as an alignment of 1, fixedSize
+// false and sizeFrames 0. Capture channel selection with SubConfig.ChannelIndices also hands the
+// callback a buffer without an alignment guarantee.
+func (dev *Device) CallbackBufferInfo() (alignment int, fixedSize bool, sizeFrames int) {
+	device := dev.cptr()
+	var buffers []unsafe.Pointer
+	switch dev.Type() {
+	case Playback:
+		buffers = []unsafe.Pointer{device.playback.pIntermediaryBuffer}
+		sizeFrames = int(device.playback.intermediaryBufferCap)
+	case Capture, Loopback:
+		buffers = []unsafe.Pointer{device.capture.pIntermediaryBuffer}
+		sizeFrames = int(device.capture.intermediaryBufferCap)
+	case Duplex:
+		buffers = []unsafe.Pointer{device.playback.pIntermediaryBuffer, device.capture.pIntermediaryBuffer}
+		sizeFrames = int(device.capture.intermediaryBufferCap)
+	}
+	if device.noFixedSizedCallback != 0 || sizeFrames == 0 {
+		return 1, false, 0
+	}
+
+	alignment = simdAlignment
+	for _, buffer := range buffers {
+		if buffer == nil {
+			return 1, false, 0
+		}
+		for alignment > 1 && uintptr(buffer)%uintptr(alignment) != 0 {
+			alignment /= 2
+		}
+	}
+	if dev.Type() != Playback && len(dev.config.Capture.ChannelIndices) > 0 {
+		alignment = 1
+	}
+	return alignment, true, sizeFrames
+}
+
 // Type returns device type.
 func (dev *Device) Type() DeviceType {
 	return DeviceType(dev.cptr()._type)
@@ -110,7 +471,15 @@ func (dev *Device) SampleRate() uint32 {
 //
 // This API waits until the backend device has been started for real by the worker thread. It also
 // waits on a mutex for thread-safety.
+//
+// With DeviceConfig.StartFadeMilliseconds set, the playback output fades in from silence.
 func (dev *Device) Start() error {
+	if dev.dead {
+		return ErrDeviceNotInitialized
+	}
+	if dev.fade != nil && !dev.IsStarted() {
+		dev.fade.reset()
+	}
 	result := C.ma_device_start(dev.cptr())
 	return errorFromResult(result)
 }
@@ -127,32 +496,243 @@ func (dev *Device) IsStarted() bool {
 // also waits on a mutex for thread-safety. In addition, some backends need to wait for the device to
 // finish playback/recording of the current fragment which can take some time (usually proportionate to
 // the buffer size that was specified at initialization time).
+//
+// With DeviceConfig.StopFadeMilliseconds set, the playback output fades out to silence before the
+// device is stopped, which delays the return by the fade time.
 func (dev *Device) Stop() error {
+	if dev.dead {
+		return ErrDeviceNotInitialized
+	}
+	if dev.fade != nil && dev.fade.stopStep > 0 && dev.IsStarted() {
+		atomic.StoreUint32(&dev.fade.fadingOut, 1)
+		// Give up waiting if the backend stopped calling back, so Stop cannot hang.
+		timeout := time.Duration(2*dev.config.StopFadeMilliseconds+100) * time.Millisecond
+		select {
+		case <-dev.fade.faded:
+		case <-time.After(timeout):
+		}
+	}
 	result := C.ma_device_stop(dev.cptr())
 	return errorFromResult(result)
 }
 
+// SetMasterPan sets the left/right balance of the playback output, from -1 (left only) through
+// 0 (centered) to 1 (right only). Values outside this range are clamped.
+//
+// This is a balance control rather than a true pan: moving towards one side attenuates the opposite
+// channel linearly and leaves the other untouched, so the channels are never mixed. It is applied
+// after the data callback and is only available for stereo playback; ErrInvalidOperation is
+// returned for other devices.
+func (dev *Device) SetMasterPan(pan float32) error {
+	if math.IsNaN(float64(pan)) {
+		return ErrInvalidArgs
+	}
+	if dev.Type() == Capture || dev.Type() == Loopback || dev.PlaybackChannels() != 2 {
+		return ErrInvalidOperation
+	}
+
+	storeMasterPan(dev.pan, pan)
+	return nil
+}
+
+// storeMasterPan stores the pan read by applyBalance, clamped to [-1, 1].
+func storeMasterPan(dst *uint32, pan float32) {
+	atomic.StoreUint32(dst, math.Float32bits(float32(clamp(float64(pan), -1, 1))))
+}
+
+// MasterPan returns the balance set with SetMasterPan.
+func (dev *Device) MasterPan() float32 {
+	return math.Float32frombits(atomic.LoadUint32(dev.pan))
+}
+
+// SwitchDevice moves the playback or capture side of the device to the device with the given ID.
+// A nil ID selects the default device.
+//
+// The device is reinitialized in place and restarted if it was running. The registered callbacks,
+// the master volume, and the format, channel count and sample rate seen by the callbacks are kept,
+// so a different native format on the new device is converted by miniaudio.
+//
+// The Stop callback is called during the switch if the device was running, as miniaudio stops it
+// before reinitializing. If neither the new nor the previous device can be opened, the device is left
+// unusable: its callbacks are unregistered, Start and Stop return ErrDeviceNotInitialized, and Uninit
+// only releases its memory.
+func (dev *Device) SwitchDevice(kind DeviceType, id *DeviceID) error {
+	if dev.dead {
+		return ErrDeviceNotInitialized
+	}
+	rawDevice := dev.cptr()
+	deviceType := dev.Type()
+	switch {
+	case kind == Playback && (deviceType == Playback || deviceType == Duplex):
+	case kind == Capture && deviceType != Playback:
+	default:
+		return ErrInvalidArgs
+	}
+
+	config := dev.config
+	config.SampleRate = dev.SampleRate()
+	config.Playback.Format = dev.PlaybackFormat()
+	config.Playback.Channels = dev.PlaybackChannels()
+	config.Capture.Format = dev.CaptureFormat()
+	config.Capture.Channels = dev.CaptureChannels()
+	config.Playback.DeviceID = nil
+	config.Capture.DeviceID = nil
+
+	// Device IDs are copied to C memory so they stay valid while the device is reinitialized.
+	var previousIDs []unsafe.Pointer
+	if rawDevice.playback.pID != nil {
+		previousID := DeviceID(rawDevice.playback.id)
+		config.Playback.DeviceID = previousID.Pointer()
+		previousIDs = append(previousIDs, config.Playback.DeviceID)
+	}
+	if rawDevice.capture.pID != nil {
+		previousID := DeviceID(rawDevice.capture.id)
+		config.Capture.DeviceID = previousID.Pointer()
+		previousIDs = append(previousIDs, config.Capture.DeviceID)
+	}
+	defer func() {
+		for _, ptr := range previousIDs {
+			C.ma_free(ptr, nil)
+		}
+	}()
+
+	newConfig := config
+	var newID unsafe.Pointer
+	if id != nil {
+		newID = id.Pointer()
+		defer C.ma_free(newID, nil)
+	}
+	if kind == Playback {
+		newConfig.Playback.DeviceID = newID
+	} else {
+		newConfig.Capture.DeviceID = newID
+	}
+
+	wasStarted := dev.IsStarted()
+	var volume C.float
+	C.ma_device_get_master_volume(rawDevice, &volume)
+	pContext := rawDevice.pContext
+
+	C.ma_device_uninit(rawDevice)
+	err := dev.reinit(pContext, newConfig)
+	if err != nil {
+		// Fall back to the previous device so the instance stays usable.
+		if dev.reinit(pContext, config) != nil {
+			unregisterDevice(rawDevice)
+			dev.dead = true
+			return err
+		}
+	}
+
+	C.ma_device_set_master_volume(rawDevice, volume)
+	if wasStarted {
+		if startErr := dev.Start(); err == nil {
+			err = startErr
+		}
+	}
+	return err
+}
+
+func (dev *Device) reinit(pContext *C.ma_context, config DeviceConfig) error {
+	devConfigC, release := config.toC()
+	defer release()
+
+	rawDevice := dev.cptr()
+	deviceMutex.Lock()
+	_, notify := notificationCallbacks[rawDevice]
+	deviceMutex.Unlock()
+
+	C.goSetDeviceConfigCallbacks(&devConfigC)
+	if notify {
+		C.goSetDeviceConfigNotificationCallback(&devConfigC)
+	}
+	result := C.ma_device_init(pContext, &devConfigC, rawDevice)
+	if result == 0 && notify {
+		deviceMutex.Lock()
+		deviceFormats[rawDevice] = deviceNativeFormats(rawDevice)
+		deviceMutex.Unlock()
+	}
+	return errorFromResult(result)
+}
+
 // Uninit uninitializes a device.
 //
 // This will explicitly stop the device. You do not need to call Stop() beforehand, but it's
 // harmless if you do.
 func (dev *Device) Uninit() {
-	rawDevice := dev.cptr()
+	if !dev.dead {
+		rawDevice := dev.cptr()
+		unregisterDevice(rawDevice)
+		C.ma_device_uninit(rawDevice)
+	}
+	dev.free()
+}
+
+// unregisterDevice removes the callbacks and state kept for a device.
+func unregisterDevice(rawDevice *C.ma_device) {
 	deviceMutex.Lock()
 	delete(dataCallbacks, rawDevice)
 	delete(stopCallbacks, rawDevice)
+	delete(notificationCallbacks, rawDevice)
+	delete(deviceFormats, rawDevice)
+	delete(callbackErrors, rawDevice)
 	deviceMutex.Unlock()
+}
 
-	C.ma_device_uninit(rawDevice)
-	dev.free()
+// CallbackPanicError describes a panic recovered from a device callback.
+type CallbackPanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("malgo: device callback panicked: %v", e.Value)
+}
+
+// LastCallbackError returns the most recent panic recovered from the device's data, stop or notification callback
+// as a *CallbackPanicError, or nil if none of them panicked.
+//
+// A panic must not unwind through miniaudio's C code, so it is recovered in the callback, logged
+// with its stack trace through the context's LogProc, and the playback output of that period is
+// silenced. The device keeps running and calls the callback again for the next period.
+func (dev *Device) LastCallbackError() error {
+	deviceMutex.Lock()
+	defer deviceMutex.Unlock()
+	return callbackErrors[dev.cptr()]
 }
 
 var deviceMutex sync.Mutex
 var dataCallbacks = make(map[*C.ma_device]DataProc)
 var stopCallbacks = make(map[*C.ma_device]StopProc)
+var callbackErrors = make(map[*C.ma_device]error)
+
+// recoverCallback records and logs a panic of a device callback.
+func recoverCallback(pDevice *C.ma_device, value interface{}) {
+	err := &CallbackPanicError{Value: value, Stack: debug.Stack()}
+
+	deviceMutex.Lock()
+	callbackErrors[pDevice] = err
+	deviceMutex.Unlock()
+
+	contextMutex.Lock()
+	logProc := logProcMap[pDevice.pContext]
+	contextMutex.Unlock()
+	if logProc != nil {
+		logProc(fmt.Sprintf("%v\n%s", err, err.Stack))
+	}
+}
 
 //export goDataCallback
 func goDataCallback(pDevice *C.ma_device, pOutput, pInput unsafe.Pointer, frameCount C.ma_uint32) {
+	defer func() {
+		if r := recover(); r != nil {
+			if pOutput != nil {
+				C.ma_silence_pcm_frames(pOutput, C.ma_uint64(frameCount), pDevice.playback.format, pDevice.playback.channels)
+			}
+			recoverCallback(pDevice, r)
+		}
+	}()
+
 	deviceMutex.Lock()
 	callback := dataCallbacks[pDevice]
 	deviceMutex.Unlock()
@@ -178,6 +758,12 @@ func goDataCallback(pDevice *C.ma_device, pOutput, pInput unsafe.Pointer, frameC
 
 //export goStopCallback
 func goStopCallback(pDevice *C.ma_device) {
+	defer func() {
+		if r := recover(); r != nil {
+			recoverCallback(pDevice, r)
+		}
+	}()
+
 	deviceMutex.Lock()
 	callback := stopCallbacks[pDevice]
 	deviceMutex.Unlock()
@@ -7,6 +7,9 @@ import (
 )
 
 // DeviceConfig type.
+//
+// The priority and stack size of the device's audio thread are set for the whole context with
+// ContextConfig.ThreadPriority and ContextConfig.ThreadStackSize.
 type DeviceConfig struct {
 	DeviceType                DeviceType
 	SampleRate                uint32
@@ -17,12 +20,14 @@ type DeviceConfig struct {
 	NoPreSilencedOutputBuffer uint32
 	NoClip                    uint32
 	NoDisableDenormals        uint32
-	NoFixedSizedCallback      uint32
+	NoFixedSizedCallback      uint32 // When set, the frame count passed to the data callback varies between calls and may be smaller or larger than the period size.
+	StartFadeMilliseconds     uint32 // Length of the fade in of the playback output after Start. Zero disables it.
+	StopFadeMilliseconds      uint32 // Length of the fade out of the playback output before Stop returns. Zero disables it.
 	DataCallback              *[0]byte
 	NotificationCallback      *[0]byte
 	StopCallback              *[0]byte
 	PUserData                 *byte
-	Resampling                ResampleConfig
+	Resampling                ResampleConfig // Used by both directions of a duplex device. miniaudio has no per-direction resampler settings.
 	Playback                  SubConfig
 	Capture                   SubConfig
 	Wasapi                    WasapiDeviceConfig
@@ -60,12 +65,14 @@ func DefaultDeviceConfig(deviceType DeviceType) DeviceConfig {
 	deviceConfig.Playback.Channels = uint32(config.playback.channels)
 	deviceConfig.Playback.ChannelMap = unsafe.Pointer(config.playback.pChannelMap)
 	deviceConfig.Playback.ShareMode = ShareMode(config.playback.shareMode)
+	deviceConfig.Playback.ChannelMixMode = ChannelMixModeType(config.playback.channelMixMode)
 
 	deviceConfig.Capture.DeviceID = unsafe.Pointer(config.capture.pDeviceID)
 	deviceConfig.Capture.Format = FormatType(config.capture.format)
 	deviceConfig.Capture.Channels = uint32(config.capture.channels)
 	deviceConfig.Capture.ChannelMap = unsafe.Pointer(config.capture.pChannelMap)
 	deviceConfig.Capture.ShareMode = ShareMode(config.capture.shareMode)
+	deviceConfig.Capture.ChannelMixMode = ChannelMixModeType(config.capture.channelMixMode)
 
 	deviceConfig.Wasapi.NoAutoConvertSRC = uint32(config.wasapi.noAutoConvertSRC)
 	deviceConfig.Wasapi.NoDefaultQualitySRC = uint32(config.wasapi.noDefaultQualitySRC)
@@ -113,12 +120,14 @@ func (d *DeviceConfig) toC() (C.ma_device_config, func()) {
 	deviceConfig.playback.channels = C.uint(d.Playback.Channels)
 	deviceConfig.playback.pChannelMap = (*C.ma_channel)(d.Playback.ChannelMap)
 	deviceConfig.playback.shareMode = C.ma_share_mode(d.Playback.ShareMode)
+	deviceConfig.playback.channelMixMode = C.ma_channel_mix_mode(d.Playback.ChannelMixMode)
 
 	deviceConfig.capture.pDeviceID = (*C.ma_device_id)(d.Capture.DeviceID)
 	deviceConfig.capture.format = C.ma_format(d.Capture.Format)
 	deviceConfig.capture.channels = C.uint(d.Capture.Channels)
 	deviceConfig.capture.pChannelMap = (*C.ma_channel)(d.Capture.ChannelMap)
 	deviceConfig.capture.shareMode = C.ma_share_mode(d.Capture.ShareMode)
+	deviceConfig.capture.channelMixMode = C.ma_channel_mix_mode(d.Capture.ChannelMixMode)
 
 	deviceConfig.wasapi.noAutoConvertSRC = C.uchar(d.Wasapi.NoAutoConvertSRC)
 	deviceConfig.wasapi.noDefaultQualitySRC = C.uchar(d.Wasapi.NoDefaultQualitySRC)
@@ -154,6 +163,12 @@ func (d *DeviceConfig) toC() (C.ma_device_config, func()) {
 }
 
 // SubConfig type.
+//
+// miniaudio converts between Format and the native format of the device with a data converter whose
+// channel conversion and resampling run in an intermediate format that cannot be configured. It is the
+// format on the device side of the conversion if that is FormatS16 or FormatF32, else the one on the
+// callback side if that is, else FormatF32. With FormatS16 on both sides there is no float stage, so
+// dither and quantization only happen where the format changes.
 type SubConfig struct {
 	DeviceID   unsafe.Pointer
 	Format     FormatType
@@ -161,7 +176,28 @@ type SubConfig struct {
 	ChannelMap unsafe.Pointer
 	ShareMode  ShareMode
 
-	// Unexposed: channelMixMode, calculateLFEFromSpatialChannels
+	// ChannelIndices selects a subset of the capture channels, in the given order, to pass to the data
+	// callback. No backend supports selecting channels natively, so all channels are captured and the
+	// selected ones are extracted before the callback is called. Only used for capture.
+	ChannelIndices []int
+
+	// ChannelMixMode selects how miniaudio converts between the channel count of the callback and the
+	// one of the device. Requesting a Channels count that differs from the device's, such as 1 on a
+	// stereo capture interface, makes miniaudio convert the channels internally before the data callback
+	// sees them, and this mode decides how they are combined. miniaudio has no way of passing channel
+	// weights to the device's converter, so ChannelMixModeCustomWeights is rejected by InitDevice.
+	ChannelMixMode ChannelMixModeType
+
+	// UseSystemDefaultFormat makes InitDevice query the native formats of the device and use the sample
+	// format, channel count and sample rate of the first one reported, so that no conversion happens in
+	// miniaudio. Backends list native formats in no particular order, so this is the system default only
+	// where the backend reports a single format, as with the mix format of WASAPI in shared mode. It
+	// overrides Format, Channels and DeviceConfig.SampleRate for every value the device reports; the
+	// negotiated values are returned by the Device accessors. When both directions of a duplex device set
+	// it, the playback device decides the sample rate.
+	UseSystemDefaultFormat bool
+
+	// Unexposed: calculateLFEFromSpatialChannels
 }
 
 // WasapiDeviceConfig type.
@@ -196,6 +232,12 @@ type ResampleConfig struct {
 
 // ResampleLinearConfig type.
 type ResampleLinearConfig struct {
+	// LpfOrder is the order of the anti-aliasing low-pass filter, up to 8. Zero is passed through
+	// as is and disables the filter, which produces aliasing when downsampling.
+	//
+	// Unlike miniaudio, where the order defaults to DefaultLpfOrder, Go gives a zero value: a
+	// ConverterConfig that does not set it resamples without a filter. DefaultDeviceConfig starts
+	// from miniaudio's defaults and sets DefaultLpfOrder.
 	LpfOrder uint32
 }
 
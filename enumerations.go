@@ -18,6 +18,9 @@ const (
 	BackendAaudio
 	BackendOpensl
 	BackendWebaudio
+	BackendCustom
+	// BackendNull runs devices on a simulated clock without any audio hardware. Playback output is
+	// discarded and capture input is silence, which makes it suitable for headless testing.
 	BackendNull
 )
 
@@ -138,3 +141,65 @@ const (
 	ChannelMixModeCustomWeights
 	ChannelMixModeDefault = ChannelMixModeRectangular
 )
+
+// Channel type.
+type Channel uint8
+
+// Channel enumeration.
+const (
+	ChannelNone Channel = iota
+	ChannelMono
+	ChannelFrontLeft
+	ChannelFrontRight
+	ChannelFrontCenter
+	ChannelLFE
+	ChannelBackLeft
+	ChannelBackRight
+	ChannelFrontLeftCenter
+	ChannelFrontRightCenter
+	ChannelBackCenter
+	ChannelSideLeft
+	ChannelSideRight
+	ChannelTopCenter
+	ChannelTopFrontLeft
+	ChannelTopFrontCenter
+	ChannelTopFrontRight
+	ChannelTopBackLeft
+	ChannelTopBackCenter
+	ChannelTopBackRight
+	ChannelAux0
+	ChannelAux1
+	ChannelAux2
+	ChannelAux3
+	ChannelAux4
+	ChannelAux5
+	ChannelAux6
+	ChannelAux7
+	ChannelAux8
+	ChannelAux9
+	ChannelAux10
+	ChannelAux11
+	ChannelAux12
+	ChannelAux13
+	ChannelAux14
+	ChannelAux15
+	ChannelAux16
+	ChannelAux17
+	ChannelAux18
+	ChannelAux19
+	ChannelAux20
+	ChannelAux21
+	ChannelAux22
+	ChannelAux23
+	ChannelAux24
+	ChannelAux25
+	ChannelAux26
+	ChannelAux27
+	ChannelAux28
+	ChannelAux29
+	ChannelAux30
+	ChannelAux31
+
+	ChannelLeft  = ChannelFrontLeft
+	ChannelRight = ChannelFrontRight
+)
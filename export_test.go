@@ -0,0 +1,10 @@
+package malgo
+
+// LPF2ImpulseResponse exposes the miniaudio reference filter to the tests of package malgo_test.
+var LPF2ImpulseResponse = lpf2ImpulseResponse
+
+// ClosestNativeFormat exposes the format matching of Context.SupportsFormat.
+var ClosestNativeFormat = closestNativeFormat
+
+// ApplyNativeFormat exposes the native format selection of SubConfig.UseSystemDefaultFormat.
+var ApplyNativeFormat = (*SubConfig).applyNativeFormat
@@ -0,0 +1,62 @@
+package malgo
+
+import (
+	"math"
+)
+
+// ChannelIdentificationFrequency is the base frequency in Hz used by GenerateChannelIdentification.
+const ChannelIdentificationFrequency = 250
+
+// GenerateChannelIdentification generates interleaved frames where every channel carries its own sine tone.
+//
+// Channel N is a tone at ChannelIdentificationFrequency*(N+1) Hz at half of full scale, so after a conversion
+// the origin of each output channel can be identified by its dominant frequency. Choose a sample rate high enough
+// for the highest channel tone to stay below the Nyquist frequency.
+//
+// Returns nil for an unknown format.
+func GenerateChannelIdentification(format FormatType, sampleRate, channels, frameCount int) []byte {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || sampleRate <= 0 || channels <= 0 || frameCount <= 0 {
+		return nil
+	}
+
+	frames := make([]byte, frameCount*channels*sampleSize)
+	for frame := 0; frame < frameCount; frame++ {
+		t := float64(frame) / float64(sampleRate)
+		for channel := 0; channel < channels; channel++ {
+			frequency := float64(ChannelIdentificationFrequency * (channel + 1))
+			setSampleAt(format, frames, frame*channels+channel, 0.5*math.Sin(2*math.Pi*frequency*t))
+		}
+	}
+
+	return frames
+}
+
+// BuildInterleaved generates interleaved frames where channel N carries the output of generators[N], so
+// len(generators) is the channel count.
+//
+// Each generator returns the normalized sample of its channel for a frame index; a generator that depends
+// on time can compute it as frame/sampleRate. Integer formats saturate outside [-1, 1]. ErrInvalidArgs is
+// returned for an unknown format, a sample rate below one, a negative frame count, a nil generator or more
+// than MaxChannels generators.
+func BuildInterleaved(format FormatType, sampleRate, frameCount int, generators []func(frame int) float32) ([]byte, error) {
+	sampleSize := SampleSizeInBytes(format)
+	channels := len(generators)
+	if sampleSize == 0 || sampleRate <= 0 || frameCount < 0 || channels == 0 || channels > MaxChannels {
+		return nil, ErrInvalidArgs
+	}
+	for _, generator := range generators {
+		if generator == nil {
+			return nil, ErrInvalidArgs
+		}
+	}
+
+	frames := make([]byte, frameCount*channels*sampleSize)
+	for frame := 0; frame < frameCount; frame++ {
+		for channel, generator := range generators {
+			setSampleAt(format, frames, frame*channels+channel, float64(generator(frame)))
+		}
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,61 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestGenerateChannelIdentification(t *testing.T) {
+	const sampleRate = 48000
+	const channels = 4
+
+	frames := malgo.GenerateChannelIdentification(malgo.FormatS16, sampleRate, channels, sampleRate)
+	assertEqual(t, sampleRate*channels*2, len(frames), "Unexpected buffer size")
+
+	for channel := 0; channel < channels; channel++ {
+		crossings := 0
+		previous := int16(0)
+		for frame := 0; frame < sampleRate; frame++ {
+			sample := int16(binary.LittleEndian.Uint16(frames[(frame*channels+channel)*2:]))
+			if (previous < 0) != (sample < 0) {
+				crossings++
+			}
+			previous = sample
+		}
+		expected := 2 * malgo.ChannelIdentificationFrequency * (channel + 1)
+		if crossings < expected-2 || crossings > expected+2 {
+			t.Fatalf("channel %d: expected about %d zero crossings, got %d", channel, expected, crossings)
+		}
+	}
+
+	assertTrue(t, malgo.GenerateChannelIdentification(malgo.FormatUnknown, sampleRate, channels, 16) == nil, "Expected nil for unknown format")
+}
+
+func TestBuildInterleaved(t *testing.T) {
+	generators := []func(frame int) float32{
+		func(frame int) float32 { return 0.5 },
+		func(frame int) float32 { return float32(frame) / 4 },
+		func(frame int) float32 { return -2 },
+	}
+
+	frames, err := malgo.BuildInterleaved(malgo.FormatS16, 48000, 4, generators)
+	assertNil(t, err, "No error expected building frames")
+	assertEqual(t, 4*3*2, len(frames), "Unexpected buffer size")
+	for frame := 0; frame < 4; frame++ {
+		sample := func(channel int) int16 {
+			return int16(binary.LittleEndian.Uint16(frames[(frame*3+channel)*2:]))
+		}
+		assertEqual(t, int16(16384), sample(0), "Unexpected constant channel")
+		assertEqual(t, int16(frame*8192), sample(1), "Unexpected ramp channel")
+		assertEqual(t, int16(-32768), sample(2), "Expected saturation")
+	}
+
+	_, err = malgo.BuildInterleaved(malgo.FormatS16, 48000, 4, nil)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error without generators")
+	_, err = malgo.BuildInterleaved(malgo.FormatS16, 48000, 4, []func(frame int) float32{nil})
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a nil generator")
+	_, err = malgo.BuildInterleaved(malgo.FormatUnknown, 48000, 4, generators)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for an unknown format")
+}
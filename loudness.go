@@ -0,0 +1,187 @@
+package malgo
+
+// #include "malgo.h"
+import "C"
+import (
+	"math"
+)
+
+// LoudnessMeter measures loudness according to ITU-R BS.1770 across consecutive blocks of frames.
+//
+// Every channel is K-weighted, and the mean square is taken over 400 ms blocks overlapping by 75%.
+// Channels are weighted by their position in miniaudio's default channel layout: surround channels
+// count 1.41 times and the LFE channel is ignored.
+type LoudnessMeter struct {
+	channels        int
+	weights         []float64
+	shelves         []biquadFilter
+	highPasses      []biquadFilter
+	stepFrames      int
+	stepFrameCount  int
+	stepPower       float64
+	recentSteps     [4]float64
+	recentStepCount int
+	blockPowers     []float64
+}
+
+// NewLoudnessMeter creates a loudness meter for interleaved frames with the given channel count and sample rate.
+func NewLoudnessMeter(channels, sampleRate int) (*LoudnessMeter, error) {
+	if channels <= 0 || sampleRate <= 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	// K-weighting: a +4 dB high shelf modelling the head, followed by the RLB high-pass filter.
+	shelf := kWeightingShelf(float64(sampleRate))
+	highPass := DesignHPF(float64(sampleRate), 38.13547087602444, 0.5003270373238773)
+
+	meter := &LoudnessMeter{
+		channels:   channels,
+		weights:    make([]float64, channels),
+		shelves:    make([]biquadFilter, channels),
+		highPasses: make([]biquadFilter, channels),
+		stepFrames: sampleRate / 10,
+	}
+	for channel := 0; channel < channels; channel++ {
+		meter.weights[channel] = loudnessWeight(C.ma_channel_map_get_channel(nil, C.ma_uint32(channels), C.ma_uint32(channel)))
+		meter.shelves[channel].coefficients = shelf
+		meter.highPasses[channel].coefficients = highPass
+	}
+
+	return meter, nil
+}
+
+// kWeightingShelf designs the BS.1770 pre-filter. Unlike the cookbook shelf its band edge gain is
+// set separately, so it is computed here from the reference parameters.
+func kWeightingShelf(sampleRate float64) BiquadCoefficients {
+	const (
+		frequency = 1681.974450955533
+		gainDB    = 3.999843853973347
+		q         = 0.7071752369554196
+	)
+	k := math.Tan(math.Pi * frequency / sampleRate)
+	vh := math.Pow(10, gainDB/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	return BiquadCoefficients{
+		B0: vh + vb*k/q + k*k,
+		B1: 2 * (k*k - vh),
+		B2: vh - vb*k/q + k*k,
+		A0: 1 + k/q + k*k,
+		A1: 2 * (k*k - 1),
+		A2: 1 - k/q + k*k,
+	}.normalized()
+}
+
+// loudnessWeight returns the BS.1770 weight of a channel position.
+func loudnessWeight(position C.ma_channel) float64 {
+	switch position {
+	case C.MA_CHANNEL_LFE:
+		return 0
+	case C.MA_CHANNEL_BACK_LEFT, C.MA_CHANNEL_BACK_RIGHT, C.MA_CHANNEL_SIDE_LEFT, C.MA_CHANNEL_SIDE_RIGHT:
+		return 1.41
+	default:
+		return 1
+	}
+}
+
+// Process adds frameCount interleaved frames to the measurement.
+func (m *LoudnessMeter) Process(format FormatType, frames []byte, frameCount int) error {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || frameCount < 0 || len(frames) < frameCount*m.channels*sampleSize {
+		return ErrInvalidArgs
+	}
+
+	for frame := 0; frame < frameCount; frame++ {
+		for channel := 0; channel < m.channels; channel++ {
+			x := sampleAt(format, frames, frame*m.channels+channel)
+			y := m.highPasses[channel].process(m.shelves[channel].process(x))
+			m.stepPower += m.weights[channel] * y * y
+		}
+
+		m.stepFrameCount++
+		if m.stepFrameCount == m.stepFrames {
+			m.completeStep()
+		}
+	}
+
+	return nil
+}
+
+// completeStep closes a 100 ms step and records the 400 ms block ending with it.
+func (m *LoudnessMeter) completeStep() {
+	copy(m.recentSteps[:], m.recentSteps[1:])
+	m.recentSteps[len(m.recentSteps)-1] = m.stepPower / float64(m.stepFrameCount)
+	m.stepPower = 0
+	m.stepFrameCount = 0
+
+	if m.recentStepCount < len(m.recentSteps) {
+		m.recentStepCount++
+	}
+	if m.recentStepCount == len(m.recentSteps) {
+		m.blockPowers = append(m.blockPowers, m.MomentaryPower())
+	}
+}
+
+// MomentaryPower returns the weighted mean square of the last complete 400 ms block, or zero if
+// less than 400 ms have been processed.
+func (m *LoudnessMeter) MomentaryPower() float64 {
+	if m.recentStepCount < len(m.recentSteps) {
+		return 0
+	}
+	sum := 0.0
+	for _, power := range m.recentSteps {
+		sum += power
+	}
+	return sum / float64(len(m.recentSteps))
+}
+
+// MomentaryLUFS returns the loudness of the last complete 400 ms block.
+// It is negative infinity if less than 400 ms have been processed.
+func (m *LoudnessMeter) MomentaryLUFS() float64 {
+	return powerToLUFS(m.MomentaryPower())
+}
+
+// IntegratedLUFS returns the gated loudness of everything processed so far.
+// It is negative infinity if no block passes the gates.
+func (m *LoudnessMeter) IntegratedLUFS() float64 {
+	gated := func(threshold float64) (float64, int) {
+		sum, count := 0.0, 0
+		for _, power := range m.blockPowers {
+			if powerToLUFS(power) > threshold {
+				sum += power
+				count++
+			}
+		}
+		return sum, count
+	}
+
+	// Absolute gate at -70 LUFS, then a relative gate 10 LU below the loudness of the remaining blocks.
+	sum, count := gated(-70)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	sum, count = gated(math.Max(-70, powerToLUFS(sum/float64(count))-10))
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	return powerToLUFS(sum / float64(count))
+}
+
+func powerToLUFS(power float64) float64 {
+	if power <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(power)
+}
+
+// MeasureLUFS returns the integrated loudness of interleaved frames according to ITU-R BS.1770.
+// It is negative infinity for buffers shorter than 400 ms or quieter than -70 LUFS.
+func MeasureLUFS(format FormatType, frames []byte, frameCount, channels, sampleRate int) (float64, error) {
+	meter, err := NewLoudnessMeter(channels, sampleRate)
+	if err != nil {
+		return 0, err
+	}
+	if err := meter.Process(format, frames, frameCount); err != nil {
+		return 0, err
+	}
+	return meter.IntegratedLUFS(), nil
+}
@@ -0,0 +1,57 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func sineF32(frequency, amplitude float64, sampleRate, frameCount, channels int) []byte {
+	frames := make([]byte, frameCount*channels*4)
+	for i := 0; i < frameCount; i++ {
+		sample := float32(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		for channel := 0; channel < channels; channel++ {
+			binary.LittleEndian.PutUint32(frames[(i*channels+channel)*4:], math.Float32bits(sample))
+		}
+	}
+	return frames
+}
+
+func TestMeasureLUFS(t *testing.T) {
+	const sampleRate = 48000
+
+	// A full scale 997 Hz sine on one channel reads -3.01 LUFS by definition.
+	lufs, err := malgo.MeasureLUFS(malgo.FormatF32, sineF32(997, 1, sampleRate, sampleRate*3, 1), sampleRate*3, 1, sampleRate)
+	assertNil(t, err, "No error expected measuring loudness")
+	if math.Abs(lufs+3.01) > 0.1 {
+		t.Fatalf("expected -3.01 LUFS, got %.2f", lufs)
+	}
+
+	// The same tone at -20 dBFS on both stereo channels adds 3 dB of loudness.
+	lufs, err = malgo.MeasureLUFS(malgo.FormatF32, sineF32(997, 0.1, sampleRate, sampleRate*3, 2), sampleRate*3, 2, sampleRate)
+	assertNil(t, err, "No error expected measuring loudness")
+	if math.Abs(lufs+20) > 0.1 {
+		t.Fatalf("expected -20 LUFS, got %.2f", lufs)
+	}
+
+	lufs, err = malgo.MeasureLUFS(malgo.FormatS16, make([]byte, sampleRate*2), sampleRate, 1, sampleRate)
+	assertNil(t, err, "No error expected measuring silence")
+	assertTrue(t, math.IsInf(lufs, -1), "Expected silence to be gated")
+}
+
+func TestLoudnessMeterMomentary(t *testing.T) {
+	meter, err := malgo.NewLoudnessMeter(1, 48000)
+	assertNil(t, err, "No error expected creating loudness meter")
+
+	err = meter.Process(malgo.FormatF32, sineF32(997, 1, 48000, 12000, 1), 12000)
+	assertNil(t, err, "No error expected processing frames")
+	assertTrue(t, math.IsInf(meter.MomentaryLUFS(), -1), "Expected no momentary loudness before 400 ms")
+
+	err = meter.Process(malgo.FormatF32, sineF32(997, 1, 48000, 12000, 1), 12000)
+	assertNil(t, err, "No error expected processing frames")
+	if math.Abs(meter.MomentaryLUFS()+3.01) > 0.2 {
+		t.Fatalf("expected momentary loudness of -3.01 LUFS, got %.2f", meter.MomentaryLUFS())
+	}
+}
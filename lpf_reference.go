@@ -0,0 +1,32 @@
+package malgo
+
+// #include "malgo.h"
+import "C"
+import (
+	"unsafe"
+)
+
+// lpf2ImpulseResponse returns the first length samples of the impulse response of miniaudio's
+// second order low-pass filter, which serves as the reference for DesignLPF.
+func lpf2ImpulseResponse(sampleRate uint32, cutoff, q float64, length int) ([]float32, error) {
+	if length <= 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	config := C.ma_lpf2_config_init(C.ma_format_f32, 1, C.ma_uint32(sampleRate), C.double(cutoff), C.double(q))
+	var lpf C.ma_lpf2
+	result := C.ma_lpf2_init(&config, nil, &lpf)
+	if result != 0 {
+		return nil, errorFromResult(result)
+	}
+	defer C.ma_lpf2_uninit(&lpf, nil)
+
+	in := make([]float32, length)
+	in[0] = 1
+	out := make([]float32, length)
+	result = C.ma_lpf2_process_pcm_frames(&lpf, unsafe.Pointer(&out[0]), unsafe.Pointer(&in[0]), C.ma_uint64(length))
+	if result != 0 {
+		return nil, errorFromResult(result)
+	}
+	return out, nil
+}
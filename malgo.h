@@ -10,10 +10,15 @@ extern "C" {
 extern void goLogCallback(ma_context* pContext, char* message);
 void goSetContextConfigCallbacks(ma_context_config* pConfig, ma_context* pContext);
 
+ma_int32 goSwapDitherState(ma_int32 state);
+
 extern void goDataCallback(ma_device *pDevice, void *pOutput, void *pInput, ma_uint32 frameCount);
 extern void goStopCallback(ma_device* pDevice);
 void goSetDeviceConfigCallbacks(ma_device_config* pConfig);
 
+extern void goNotificationCallback(ma_device_notification* pNotification);
+void goSetDeviceConfigNotificationCallback(ma_device_config* pConfig);
+
 #ifdef __cplusplus
 }
 #endif
@@ -16,6 +16,14 @@ void goSetContextConfigCallbacks(ma_context_config* pConfig, ma_context* pContex
     pConfig->pLog = log;
 }
 
+// The dither noise of all converters comes from a single generator. Swapping its state allows
+// a converter to keep its own reproducible noise sequence.
+ma_int32 goSwapDitherState(ma_int32 state) {
+    ma_int32 previous = g_maLCG.state;
+    g_maLCG.state = state;
+    return previous;
+}
+
 static void goDataCallbackWrapper(ma_device *pDevice,
                                   void *pOutput, const void *pInput,
                                   ma_uint32 frames)
@@ -27,3 +35,12 @@ void goSetDeviceConfigCallbacks(ma_device_config* pConfig) {
     pConfig->dataCallback = goDataCallbackWrapper;
     pConfig->stopCallback = goStopCallback;
 }
+
+static void goNotificationCallbackWrapper(const ma_device_notification* pNotification)
+{
+    goNotificationCallback((ma_device_notification *)pNotification);
+}
+
+void goSetDeviceConfigNotificationCallback(ma_device_config* pConfig) {
+    pConfig->notificationCallback = goNotificationCallbackWrapper;
+}
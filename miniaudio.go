@@ -24,16 +24,66 @@ import "C"
 
 // SampleSizeInBytes retrieves the size of a sample in bytes for the given format.
 func SampleSizeInBytes(format FormatType) int {
-	cformat := (C.ma_format)(format)
-	ret := C.ma_get_bytes_per_sample(cformat)
-	return int(ret)
+	return format.SizeInBytes()
+}
+
+// SizeInBytes returns the size of a sample in bytes, or zero for FormatUnknown and invalid values.
+func (f FormatType) SizeInBytes() int {
+	if f > FormatF32 {
+		// ma_get_bytes_per_sample indexes a table without a bounds check.
+		return 0
+	}
+	return int(C.ma_get_bytes_per_sample(C.ma_format(f)))
+}
+
+// IsFloat reports whether samples are floating point.
+func (f FormatType) IsFloat() bool {
+	return f == FormatF32
+}
+
+// IsSigned reports whether samples are signed, which includes floating point.
+func (f FormatType) IsSigned() bool {
+	switch f {
+	case FormatS16, FormatS24, FormatS32, FormatF32:
+		return true
+	}
+	return false
+}
+
+// String returns miniaudio's description of the format, such as "16-bit Signed Integer".
+func (f FormatType) String() string {
+	return C.GoString(C.ma_get_format_name(C.ma_format(f)))
 }
 
 // FrameSizeInBytes retrieves the size of a frame in bytes for the given format.
+// It is zero for an unknown format or a channel count below one.
+//
+// FormatS24 samples are 3 bytes, so with an odd channel count frames are not a multiple of 2 or 4 bytes.
+// Use WholeFrameCount rather than dividing a buffer length to detect a trailing partial frame.
 func FrameSizeInBytes(format FormatType, channels int) int {
+	if channels <= 0 {
+		return 0
+	}
 	return SampleSizeInBytes(format) * channels
 }
 
+// EnabledBackends returns the backends that were compiled into this build, in order of priority.
+func EnabledBackends() []Backend {
+	var backends [C.MA_BACKEND_COUNT]C.ma_backend
+	var count C.size_t
+
+	result := C.ma_get_enabled_backends(&backends[0], C.MA_BACKEND_COUNT, &count)
+	if result != 0 {
+		return nil
+	}
+
+	enabled := make([]Backend, int(count))
+	for i := range enabled {
+		enabled[i] = Backend(backends[i])
+	}
+	return enabled
+}
+
 const (
 	rawDeviceInfoSize = C.sizeof_ma_device_info
 )
@@ -3,6 +3,8 @@ package malgo_test
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -184,3 +186,666 @@ func TestErrors(t *testing.T) {
 
 	dev.Uninit()
 }
+
+func TestTypedDataCallback(t *testing.T) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 44100
+
+	var frames int32
+	onSendFrames := func(outputSamples, inputSamples []float32, frameCount int) {
+		if len(outputSamples) != frameCount*2 {
+			t.Errorf("wrong number of samples")
+		}
+		atomic.AddInt32(&frames, int32(frameCount))
+	}
+
+	_, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data:    func(outputSamples, inputSamples []byte, framecount uint32) {},
+		DataF32: onSendFrames,
+	})
+	if err != malgo.ErrInvalidArgs {
+		t.Fatalf("device init with multiple data callbacks")
+	}
+
+	_, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		DataS16: func(outputSamples, inputSamples []int16, frameCount int) {},
+	})
+	if err != malgo.ErrFormatNotSupported {
+		t.Fatalf("device init with mismatched typed callback")
+	}
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		DataF32: onSendFrames,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	dev.Uninit()
+
+	if atomic.LoadInt32(&frames) == 0 {
+		t.Errorf("typed callback not called")
+	}
+}
+
+func TestSwitchDevice(t *testing.T) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 44100
+
+	var frames int32
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {
+			atomic.AddInt32(&frames, int32(framecount))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if dev.SwitchDevice(malgo.Capture, nil) != malgo.ErrInvalidArgs {
+		t.Fatalf("switched capture side of playback device")
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dev.SwitchDevice(malgo.Playback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dev.IsStarted() {
+		t.Fatalf("device not restarted")
+	}
+
+	if dev.PlaybackFormat() != malgo.FormatS16 || dev.PlaybackChannels() != 2 || dev.SampleRate() != 44100 {
+		t.Errorf("format not preserved")
+	}
+
+	atomic.StoreInt32(&frames, 0)
+	time.Sleep(100 * time.Millisecond)
+	if atomic.LoadInt32(&frames) == 0 {
+		t.Errorf("callback not called after switch")
+	}
+}
+
+func TestNullBackend(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Duplex)
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.Capture.Format = malgo.FormatS16
+	deviceConfig.Capture.Channels = 2
+	deviceConfig.SampleRate = 48000
+	deviceConfig.PeriodSizeInFrames = 480
+
+	var frames, silent int32
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {
+			if framecount != 480 {
+				t.Errorf("unexpected frame count %d", framecount)
+			}
+			for _, b := range inputSamples {
+				if b != 0 {
+					return
+				}
+			}
+			atomic.AddInt32(&silent, 1)
+			atomic.AddInt32(&frames, int32(framecount))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	dev.Uninit()
+
+	// The simulated clock should have advanced by roughly half a second.
+	count := atomic.LoadInt32(&frames)
+	if count < 48000/4 || count > 48000 {
+		t.Errorf("unexpected number of frames %d", count)
+	}
+	if atomic.LoadInt32(&silent) == 0 {
+		t.Errorf("expected silent capture input")
+	}
+}
+
+func TestCaptureChannelIndices(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgo.FormatS16
+	deviceConfig.Capture.Channels = 8
+	deviceConfig.Capture.ChannelIndices = []int{2, 3}
+	deviceConfig.SampleRate = 48000
+
+	var calls int32
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {
+			if len(inputSamples) != int(framecount)*2*2 {
+				t.Errorf("unexpected input size %d for %d frames", len(inputSamples), framecount)
+			}
+			atomic.AddInt32(&calls, 1)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	dev.Uninit()
+
+	if atomic.LoadInt32(&calls) == 0 {
+		t.Errorf("callback not called")
+	}
+
+	deviceConfig.Capture.ChannelIndices = []int{8}
+	_, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {},
+	})
+	if err != malgo.ErrInvalidArgs {
+		t.Fatalf("device init with out of range channel index")
+	}
+}
+
+func TestMasterPan(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 48000
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {
+			for i := range outputSamples {
+				outputSamples[i] = 0.5
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dev.SetMasterPan(-0.5); err != nil {
+		t.Fatal(err)
+	}
+	if pan := dev.MasterPan(); pan != -0.5 {
+		t.Fatalf("unexpected pan %v", pan)
+	}
+	if err := dev.SetMasterPan(3); err != nil {
+		t.Fatal(err)
+	}
+	if pan := dev.MasterPan(); pan != 1 {
+		t.Fatalf("expected pan to be clamped, got %v", pan)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	dev.Uninit()
+
+	deviceConfig.Playback.Channels = 1
+	dev, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if err := dev.SetMasterPan(0.5); err != malgo.ErrInvalidOperation {
+		t.Fatalf("expected pan to be unavailable for mono playback, got %v", err)
+	}
+}
+
+func TestCallbackPanic(t *testing.T) {
+	var logged int32
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, func(message string) {
+		if strings.Contains(message, "device callback panicked: boom") {
+			atomic.AddInt32(&logged, 1)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 48000
+
+	var calls int32
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {
+			atomic.AddInt32(&calls, 1)
+			panic("boom")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if dev.LastCallbackError() != nil {
+		t.Fatalf("unexpected callback error before start")
+	}
+
+	err = dev.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if atomic.LoadInt32(&calls) < 2 {
+		t.Errorf("expected the device to keep calling the callback after a panic")
+	}
+	panicErr, ok := dev.LastCallbackError().(*malgo.CallbackPanicError)
+	if !ok || panicErr.Value != "boom" {
+		t.Fatalf("expected recovered panic, got %v", dev.LastCallbackError())
+	}
+	if atomic.LoadInt32(&logged) == 0 {
+		t.Errorf("expected the panic to be logged")
+	}
+}
+
+func TestFormatTypeIntrospection(t *testing.T) {
+	tests := []struct {
+		format   malgo.FormatType
+		size     int
+		isFloat  bool
+		isSigned bool
+	}{
+		{malgo.FormatUnknown, 0, false, false},
+		{malgo.FormatU8, 1, false, false},
+		{malgo.FormatS16, 2, false, true},
+		{malgo.FormatS24, 3, false, true},
+		{malgo.FormatS32, 4, false, true},
+		{malgo.FormatF32, 4, true, true},
+		{malgo.FormatType(99), 0, false, false},
+	}
+	for _, test := range tests {
+		if size := test.format.SizeInBytes(); size != test.size {
+			t.Errorf("%v: expected size %d, got %d", test.format, test.size, size)
+		}
+		if test.format.IsFloat() != test.isFloat || test.format.IsSigned() != test.isSigned {
+			t.Errorf("%v: unexpected float or signed flag", test.format)
+		}
+	}
+
+	if name := malgo.FormatS16.String(); name != "16-bit Signed Integer" {
+		t.Errorf("unexpected format name %q", name)
+	}
+}
+
+func TestDeviceChannelMixMode(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 6
+	deviceConfig.Playback.ChannelMixMode = malgo.ChannelMixModeSimple
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev.Uninit()
+
+	deviceConfig.Playback.ChannelMixMode = malgo.ChannelMixModeCustomWeights
+	_, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != malgo.ErrInvalidArgs {
+		t.Fatalf("expected custom weights to be rejected, got %v", err)
+	}
+}
+
+func TestInitDeviceWithFallback(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	rejected := malgo.DefaultDeviceConfig(malgo.Playback)
+	rejected.Playback.Format = malgo.FormatF32
+	rejected.Playback.ChannelMixMode = malgo.ChannelMixModeCustomWeights
+	fallback := malgo.DefaultDeviceConfig(malgo.Playback)
+	fallback.Playback.Format = malgo.FormatS16
+
+	dev, used, err := malgo.InitDeviceWithFallback(ctx.Context, []malgo.DeviceConfig{rejected, fallback}, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev.Uninit()
+	if used.Playback.Format != malgo.FormatS16 {
+		t.Fatalf("expected the fallback config to be used, got format %v", used.Playback.Format)
+	}
+
+	_, _, err = malgo.InitDeviceWithFallback(ctx.Context, []malgo.DeviceConfig{rejected, rejected}, malgo.DeviceCallbacks{})
+	fallbackErr, ok := err.(*malgo.FallbackError)
+	if !ok {
+		t.Fatalf("expected a FallbackError, got %v", err)
+	}
+	if len(fallbackErr.Errors) != 2 || fallbackErr.Errors[0] != malgo.ErrInvalidArgs {
+		t.Fatalf("expected one error per config, got %v", fallbackErr.Errors)
+	}
+	if !strings.Contains(err.Error(), "config 1") {
+		t.Fatalf("expected every failure to be listed, got %q", err.Error())
+	}
+}
+
+func TestCaptureChannelMixMode(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgo.FormatF32
+	deviceConfig.Capture.Channels = 1
+	deviceConfig.Capture.ChannelMixMode = malgo.ChannelMixModeRectangular
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if dev.CaptureChannels() != 1 {
+		t.Fatalf("expected a mono capture callback, got %d channels", dev.CaptureChannels())
+	}
+
+	deviceConfig.Capture.ChannelMixMode = malgo.ChannelMixModeCustomWeights
+	_, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != malgo.ErrInvalidArgs {
+		t.Fatalf("expected custom weights to be rejected, got %v", err)
+	}
+}
+
+func TestUseSystemDefaultFormat(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	// The null backend accepts any format, so the requested values are kept where it reports none.
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Duplex)
+	deviceConfig.SampleRate = 44100
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.Playback.UseSystemDefaultFormat = true
+	deviceConfig.Capture.Format = malgo.FormatF32
+	deviceConfig.Capture.Channels = 1
+	deviceConfig.Capture.UseSystemDefaultFormat = true
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if dev.PlaybackFormat() != malgo.FormatS16 || dev.PlaybackChannels() != 2 {
+		t.Fatalf("unexpected playback format %v with %d channels", dev.PlaybackFormat(), dev.PlaybackChannels())
+	}
+	if dev.CaptureFormat() != malgo.FormatF32 || dev.CaptureChannels() != 1 {
+		t.Fatalf("unexpected capture format %v with %d channels", dev.CaptureFormat(), dev.CaptureChannels())
+	}
+	if dev.SampleRate() != 44100 {
+		t.Fatalf("unexpected sample rate %d", dev.SampleRate())
+	}
+}
+
+func TestApplyNativeFormat(t *testing.T) {
+	sub := malgo.SubConfig{Format: malgo.FormatS16, Channels: 2}
+	sampleRate := uint32(44100)
+	malgo.ApplyNativeFormat(&sub, malgo.DataFormat{Format: malgo.FormatF32, Channels: 6, SampleRate: 48000}, &sampleRate)
+	if sub.Format != malgo.FormatF32 || sub.Channels != 6 || sampleRate != 48000 {
+		t.Fatalf("expected the native format, got %v with %d channels at %d", sub.Format, sub.Channels, sampleRate)
+	}
+
+	// Zero fields of the native format keep the requested values.
+	sub = malgo.SubConfig{Format: malgo.FormatS16, Channels: 2}
+	sampleRate = 44100
+	malgo.ApplyNativeFormat(&sub, malgo.DataFormat{Channels: 1}, &sampleRate)
+	if sub.Format != malgo.FormatS16 || sub.Channels != 1 || sampleRate != 44100 {
+		t.Fatalf("expected the requested format and rate to be kept, got %v with %d channels at %d", sub.Format, sub.Channels, sampleRate)
+	}
+}
+
+func TestDeviceStopFade(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 48000
+	deviceConfig.StartFadeMilliseconds = 20
+	deviceConfig.StopFadeMilliseconds = 100
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {
+			for i := range outputSamples {
+				outputSamples[i] = 0.5
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	for i := 0; i < 2; i++ {
+		if err := dev.Start(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+
+		start := time.Now()
+		if err := dev.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+			t.Fatalf("expected Stop to wait for the fade out, returned after %v", elapsed)
+		}
+	}
+}
+
+func TestCallbackBufferInfo(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 48000
+	deviceConfig.PeriodSizeInFrames = 256
+
+	frameCounts := make(chan int, 1)
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {
+			select {
+			case frameCounts <- frameCount:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alignment, fixedSize, sizeFrames := dev.CallbackBufferInfo()
+	if !fixedSize || sizeFrames != 256 {
+		t.Fatalf("expected fixed callbacks of 256 frames, got %v and %d", fixedSize, sizeFrames)
+	}
+	if alignment < 8 || alignment&(alignment-1) != 0 {
+		t.Fatalf("expected a power of two alignment of at least 8, got %d", alignment)
+	}
+
+	if err := dev.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if frameCount := <-frameCounts; frameCount != sizeFrames {
+		t.Fatalf("expected callbacks of %d frames, got %d", sizeFrames, frameCount)
+	}
+	dev.Uninit()
+
+	deviceConfig.NoFixedSizedCallback = 1
+	dev, err = malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	alignment, fixedSize, sizeFrames = dev.CallbackBufferInfo()
+	if alignment != 1 || fixedSize || sizeFrames != 0 {
+		t.Fatalf("expected no guarantees for variable callbacks, got %d, %v, %d", alignment, fixedSize, sizeFrames)
+	}
+}
+
+func TestDeviceNotification(t *testing.T) {
+	ctx, err := malgo.InitContext([]malgo.Backend{malgo.BackendNull}, malgo.ContextConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	notifications := make(chan malgo.DeviceNotificationType, 4)
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = 48000
+
+	dev, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, frameCount uint32) {},
+		Notification: func(notification malgo.DeviceNotification) {
+			notifications <- notification.Type
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Uninit()
+
+	if err := dev.Start(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case kind := <-notifications:
+		if kind != malgo.NotificationStarted {
+			t.Fatalf("expected a started notification, got %v", kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a started notification")
+	}
+	if err := dev.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package malgo
+
+import (
+	"os"
+)
+
+// mapFile is not supported on this platform, so callers fall back to reading the file.
+func mapFile(f *os.File, size int64) ([]byte, func() error, error) {
+	return nil, nil, ErrNotImplemented
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package malgo
+
+import (
+	"math"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f read-only. The returned function unmaps them.
+func mapFile(f *os.File, size int64) ([]byte, func() error, error) {
+	if size <= 0 || size > math.MaxInt {
+		return nil, nil, ErrInvalidArgs
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
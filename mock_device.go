@@ -0,0 +1,208 @@
+package malgo
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// MockDevice runs the data callback of a device on demand, without a context or backend.
+//
+// It is meant for unit tests of callback logic: every Tick calls the callback exactly once with the
+// given frame count, feeds it the queued capture input, and returns the playback output it wrote.
+type MockDevice struct {
+	config    DeviceConfig
+	dataProc  DataProc
+	notify    NotificationProc
+	native    nativeFormats
+	err       error
+	input     []byte
+	output    []byte
+	inputTick []byte
+	pan       uint32
+}
+
+// NewMockDevice creates a mock device for the device type, formats and channel counts of config.
+//
+// As there is no native device to negotiate with, the formats and channel counts of the active
+// directions must be set, except that a typed data callback implies its format. Invalid settings are
+// reported by Tick. The capture channel selection of SubConfig.ChannelIndices is applied as it would
+// be by InitDevice, the balance of SetMasterPan is applied to stereo playback output, and
+// DeviceConfig.StartFadeMilliseconds fades in the output from the first Tick, which requires
+// DeviceConfig.SampleRate to be set.
+func NewMockDevice(config DeviceConfig, callbacks DeviceCallbacks) *MockDevice {
+	dev := &MockDevice{config: config, notify: callbacks.Notification}
+
+	dataProc, dataFormat, err := callbacks.dataProc()
+	if err != nil {
+		dev.err = err
+		return dev
+	}
+	for _, sub := range []*SubConfig{&dev.config.Playback, &dev.config.Capture} {
+		if sub.Format == FormatUnknown {
+			sub.Format = dataFormat
+		}
+	}
+	if dataFormat != FormatUnknown && !dev.usesFormat(dataFormat) {
+		dev.err = ErrFormatNotSupported
+		return dev
+	}
+	if dev.hasPlayback() && (SampleSizeInBytes(dev.config.Playback.Format) == 0 || dev.config.Playback.Channels == 0) ||
+		dev.hasCapture() && (SampleSizeInBytes(dev.config.Capture.Format) == 0 || dev.config.Capture.Channels == 0) ||
+		dataProc == nil {
+		dev.err = ErrInvalidArgs
+		return dev
+	}
+	if dev.hasCapture() && len(config.Capture.ChannelIndices) > 0 {
+		dataProc, err = selectCaptureChannels(dataProc, dev.config.Capture.Format, int(dev.config.Capture.Channels), config.Capture.ChannelIndices)
+		if err != nil {
+			dev.err = err
+			return dev
+		}
+	}
+
+	if dev.hasPlayback() && dev.config.Playback.Channels == 2 {
+		dataProc = applyBalance(dataProc, dev.config.Playback.Format, &dev.pan)
+	}
+	if dev.hasPlayback() && config.StartFadeMilliseconds > 0 {
+		if config.SampleRate == 0 {
+			dev.err = ErrInvalidArgs
+			return dev
+		}
+		dataProc = applyFade(dataProc, dev.config.Playback.Format, int(dev.config.Playback.Channels), newDeviceFade(config, config.SampleRate))
+	}
+
+	if dev.hasPlayback() {
+		dev.native[0] = DataFormat{Format: dev.config.Playback.Format, Channels: dev.config.Playback.Channels, SampleRate: config.SampleRate}
+	}
+	if dev.hasCapture() {
+		dev.native[1] = DataFormat{Format: dev.config.Capture.Format, Channels: dev.config.Capture.Channels, SampleRate: config.SampleRate}
+	}
+
+	dev.dataProc = dataProc
+	return dev
+}
+
+func (dev *MockDevice) hasPlayback() bool {
+	return dev.config.DeviceType == Playback || dev.config.DeviceType == Duplex
+}
+
+func (dev *MockDevice) hasCapture() bool {
+	return dev.config.DeviceType != Playback
+}
+
+func (dev *MockDevice) usesFormat(format FormatType) bool {
+	if dev.hasPlayback() && dev.config.Playback.Format != format {
+		return false
+	}
+	if dev.hasCapture() && dev.config.Capture.Format != format {
+		return false
+	}
+	return true
+}
+
+// QueueCaptureInput appends interleaved capture frames to be fed to the callback by the following ticks.
+// Once the queue runs out, the callback receives silence.
+func (dev *MockDevice) QueueCaptureInput(frames []byte) {
+	dev.input = append(dev.input, frames...)
+}
+
+// Tick calls the data callback once with frameCount frames and returns the playback output it wrote,
+// or nil for capture devices. The returned slice is reused by the next Tick.
+//
+// The output buffer is silenced before the call unless NoPreSilencedOutputBuffer is set, in which
+// case it holds the output of the previous tick.
+func (dev *MockDevice) Tick(frameCount int) ([]byte, error) {
+	if dev.err != nil {
+		return nil, dev.err
+	}
+	if frameCount < 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	var input, output []byte
+	if dev.hasCapture() {
+		size := frameCount * FrameSizeInBytes(dev.config.Capture.Format, int(dev.config.Capture.Channels))
+		if cap(dev.inputTick) < size {
+			dev.inputTick = make([]byte, size)
+		}
+		input = dev.inputTick[:size]
+		n := copy(input, dev.input)
+		dev.input = dev.input[n:]
+		silence(dev.config.Capture.Format, input[n:])
+	}
+	if dev.hasPlayback() {
+		size := frameCount * FrameSizeInBytes(dev.config.Playback.Format, int(dev.config.Playback.Channels))
+		if cap(dev.output) < size {
+			previous := dev.output
+			dev.output = make([]byte, size)
+			copy(dev.output, previous)
+		}
+		output = dev.output[:size]
+		if dev.config.NoPreSilencedOutputBuffer == 0 {
+			silence(dev.config.Playback.Format, output)
+		}
+	}
+
+	dev.dataProc(output, input, uint32(frameCount))
+	return output, nil
+}
+
+// SetMasterPan sets the balance of the playback output as Device.SetMasterPan does, from the next Tick.
+// ErrInvalidOperation is returned unless the device has stereo playback.
+func (dev *MockDevice) SetMasterPan(pan float32) error {
+	if math.IsNaN(float64(pan)) {
+		return ErrInvalidArgs
+	}
+	if !dev.hasPlayback() || dev.config.Playback.Channels != 2 {
+		return ErrInvalidOperation
+	}
+
+	storeMasterPan(&dev.pan, pan)
+	return nil
+}
+
+// MasterPan returns the balance set with SetMasterPan.
+func (dev *MockDevice) MasterPan() float32 {
+	return math.Float32frombits(atomic.LoadUint32(&dev.pan))
+}
+
+// Reroute simulates the Playback or Capture side of the device being rerouted to a native device of
+// the given format. The notification callback receives NotificationRerouted, followed by
+// NotificationFormatChanged if the format differs from the previous one. The format seen by the data
+// callback does not change, as with a real device.
+func (dev *MockDevice) Reroute(kind DeviceType, native DataFormat) error {
+	if dev.err != nil {
+		return dev.err
+	}
+
+	current := dev.native
+	switch {
+	case kind == Playback && dev.hasPlayback():
+		current[0] = native
+	case kind == Capture && dev.hasCapture():
+		current[1] = native
+	default:
+		return ErrInvalidArgs
+	}
+	changes := dev.native.formatChanges(current)
+	dev.native = current
+
+	if dev.notify != nil {
+		dev.notify(DeviceNotification{Type: NotificationRerouted})
+		for _, change := range changes {
+			dev.notify(change)
+		}
+	}
+	return nil
+}
+
+// silence fills a buffer with the silent sample value of the format.
+func silence(format FormatType, b []byte) {
+	var value byte
+	if format == FormatU8 {
+		value = 128
+	}
+	for i := range b {
+		b[i] = value
+	}
+}
@@ -0,0 +1,192 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestMockDevice(t *testing.T) {
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Duplex)
+	deviceConfig.Playback.Channels = 1
+	deviceConfig.Capture.Channels = 2
+
+	var frameCounts []int
+	dev := malgo.NewMockDevice(deviceConfig, malgo.DeviceCallbacks{
+		DataS16: func(outputSamples, inputSamples []int16, frameCount int) {
+			frameCounts = append(frameCounts, frameCount)
+			for i := 0; i < frameCount; i++ {
+				outputSamples[i] = inputSamples[i*2] + inputSamples[i*2+1]
+			}
+		},
+	})
+
+	dev.QueueCaptureInput([]byte{1, 0, 2, 0, 3, 0, 4, 0, 5, 0, 6, 0})
+
+	out, err := dev.Tick(2)
+	assertNil(t, err, "No error expected ticking")
+	assertEqual(t, string([]byte{3, 0, 7, 0}), string(out), "Unexpected output of first tick")
+
+	out, err = dev.Tick(2)
+	assertNil(t, err, "No error expected ticking")
+	assertEqual(t, string([]byte{11, 0, 0, 0}), string(out), "Expected silence once the input runs out")
+
+	assertEqual(t, 2, len(frameCounts), "Expected one callback per tick")
+}
+
+func TestMockDeviceErrors(t *testing.T) {
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatS16
+	deviceConfig.Playback.Channels = 2
+
+	dev := malgo.NewMockDevice(deviceConfig, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {},
+	})
+	_, err := dev.Tick(1)
+	assertEqual(t, malgo.ErrFormatNotSupported, err, "Expected error for a mismatched typed callback")
+
+	deviceConfig.Playback.Channels = 0
+	dev = malgo.NewMockDevice(deviceConfig, malgo.DeviceCallbacks{
+		Data: func(outputSamples, inputSamples []byte, framecount uint32) {},
+	})
+	_, err = dev.Tick(1)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error without a channel count")
+}
+
+func TestMockDeviceStartFade(t *testing.T) {
+	config := malgo.DefaultDeviceConfig(malgo.Playback)
+	config.Playback.Channels = 1
+	config.SampleRate = 1000
+	config.StartFadeMilliseconds = 10
+
+	dev := malgo.NewMockDevice(config, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {
+			for i := range outputSamples {
+				outputSamples[i] = 1
+			}
+		},
+	})
+	output, err := dev.Tick(20)
+	assertNil(t, err, "No error expected ticking")
+
+	samples := make([]float32, 20)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(output[i*4:]))
+	}
+	assertEqual(t, float32(0.1), samples[0], "Expected the fade to start from silence")
+	assertEqual(t, float32(0.5), samples[4], "Expected a linear ramp")
+	assertEqual(t, float32(1), samples[9], "Expected the fade to last 10 frames")
+	assertEqual(t, float32(1), samples[19], "Expected full level after the fade")
+
+	config.SampleRate = 0
+	_, err = malgo.NewMockDevice(config, malgo.DeviceCallbacks{DataF32: func(_, _ []float32, _ int) {}}).Tick(1)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected a fade to require a sample rate")
+}
+
+func TestMockDeviceMasterPan(t *testing.T) {
+	config := malgo.DefaultDeviceConfig(malgo.Playback)
+	config.Playback.Channels = 2
+
+	dev := malgo.NewMockDevice(config, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {
+			for i := range outputSamples {
+				outputSamples[i] = 0.5
+			}
+		},
+	})
+	for _, test := range []struct {
+		pan         float32
+		left, right float32
+	}{
+		{-1, 0.5, 0},
+		{0, 0.5, 0.5},
+		{1, 0, 0.5},
+		{0.5, 0.25, 0.5},
+	} {
+		err := dev.SetMasterPan(test.pan)
+		assertNil(t, err, "No error expected setting the pan")
+		output, err := dev.Tick(4)
+		assertNil(t, err, "No error expected ticking")
+		for frame := 0; frame < 4; frame++ {
+			left := math.Float32frombits(binary.LittleEndian.Uint32(output[frame*8:]))
+			right := math.Float32frombits(binary.LittleEndian.Uint32(output[frame*8+4:]))
+			assertEqual(t, test.left, left, fmt.Sprintf("Unexpected left gain for pan %v", test.pan))
+			assertEqual(t, test.right, right, fmt.Sprintf("Unexpected right gain for pan %v", test.pan))
+		}
+	}
+
+	config.Playback.Channels = 1
+	dev = malgo.NewMockDevice(config, malgo.DeviceCallbacks{DataF32: func(_, _ []float32, _ int) {}})
+	err := dev.SetMasterPan(0.5)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected the pan to be unavailable for mono playback")
+}
+
+func TestMockDeviceReroute(t *testing.T) {
+	config := malgo.DefaultDeviceConfig(malgo.Playback)
+	config.Playback.Format = malgo.FormatF32
+	config.Playback.Channels = 2
+	config.SampleRate = 48000
+
+	var notifications []malgo.DeviceNotification
+	dev := malgo.NewMockDevice(config, malgo.DeviceCallbacks{
+		DataF32: func(outputSamples, inputSamples []float32, frameCount int) {},
+		Notification: func(notification malgo.DeviceNotification) {
+			notifications = append(notifications, notification)
+		},
+	})
+
+	err := dev.Reroute(malgo.Playback, malgo.DataFormat{Format: malgo.FormatF32, Channels: 2, SampleRate: 48000})
+	assertNil(t, err, "No error expected rerouting")
+	assertEqual(t, 1, len(notifications), "Expected only a reroute for the same format")
+	assertEqual(t, malgo.NotificationRerouted, notifications[0].Type, "Expected a reroute notification")
+
+	headset := malgo.DataFormat{Format: malgo.FormatS16, Channels: 1, SampleRate: 16000}
+	err = dev.Reroute(malgo.Playback, headset)
+	assertNil(t, err, "No error expected rerouting")
+	assertEqual(t, 3, len(notifications), "Expected a reroute and a format change")
+	assertEqual(t, malgo.NotificationFormatChanged, notifications[2].Type, "Expected a format change notification")
+	assertEqual(t, malgo.Playback, notifications[2].DeviceType, "Unexpected device type")
+	assertEqual(t, headset, notifications[2].Format, "Expected the new native format")
+
+	out, err := dev.Tick(4)
+	assertNil(t, err, "No error expected ticking")
+	assertEqual(t, 4*2*4, len(out), "Expected the callback format to be kept")
+
+	err = dev.Reroute(malgo.Capture, headset)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error rerouting a missing side")
+}
+
+func TestMockDeviceCaptureChannelIndices(t *testing.T) {
+	config := malgo.DefaultDeviceConfig(malgo.Capture)
+	config.Capture.Format = malgo.FormatS16
+	config.Capture.Channels = 4
+	config.Capture.ChannelIndices = []int{3, 1}
+
+	var received []int16
+	dev := malgo.NewMockDevice(config, malgo.DeviceCallbacks{
+		DataS16: func(outputSamples, inputSamples []int16, frameCount int) {
+			received = append(received, inputSamples...)
+		},
+	})
+
+	// Sample value 10*frame+channel identifies where every sample comes from.
+	const frameCount = 3
+	input := make([]byte, frameCount*4*2)
+	for frame := 0; frame < frameCount; frame++ {
+		for channel := 0; channel < 4; channel++ {
+			binary.LittleEndian.PutUint16(input[(frame*4+channel)*2:], uint16(10*frame+channel))
+		}
+	}
+	dev.QueueCaptureInput(input)
+
+	_, err := dev.Tick(frameCount)
+	assertNil(t, err, "No error expected ticking")
+	assertEqual(t, frameCount*2, len(received), "Expected two selected channels per frame")
+	for frame := 0; frame < frameCount; frame++ {
+		assertEqual(t, int16(10*frame+3), received[frame*2], "Expected channel 3 first")
+		assertEqual(t, int16(10*frame+1), received[frame*2+1], "Expected channel 1 second")
+	}
+}
@@ -0,0 +1,50 @@
+package malgo
+
+import (
+	"math"
+)
+
+// NormalizePeak applies a single gain to interleaved frames in place so that the highest absolute
+// sample reaches targetDB dBFS. Silent buffers are left unchanged.
+func NormalizePeak(format FormatType, frames []byte, frameCount, channels int, targetDB float32) error {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount < 0 || len(frames) < frameCount*channels*sampleSize {
+		return ErrInvalidArgs
+	}
+
+	peak := 0.0
+	for i := 0; i < frameCount*channels; i++ {
+		peak = math.Max(peak, math.Abs(sampleAt(format, frames, i)))
+	}
+	if peak == 0 {
+		return nil
+	}
+
+	applyGain(format, frames, frameCount*channels, dbToLinear(float64(targetDB))/peak)
+	return nil
+}
+
+// NormalizeLUFS applies a single gain to interleaved frames in place so that their integrated
+// loudness, as measured by MeasureLUFS, reaches targetLUFS. Buffers whose loudness cannot be
+// measured are left unchanged.
+//
+// Raising the loudness may push peaks past full scale; such samples are clipped.
+func NormalizeLUFS(format FormatType, frames []byte, frameCount, channels, sampleRate int, targetLUFS float64) error {
+	lufs, err := MeasureLUFS(format, frames, frameCount, channels, sampleRate)
+	if err != nil {
+		return err
+	}
+	if math.IsInf(lufs, -1) {
+		return nil
+	}
+
+	applyGain(format, frames, frameCount*channels, dbToLinear(targetLUFS-lufs))
+	return nil
+}
+
+// applyGain multiplies sampleCount samples by gain, clipping the result to full scale.
+func applyGain(format FormatType, frames []byte, sampleCount int, gain float64) {
+	for i := 0; i < sampleCount; i++ {
+		setSampleAt(format, frames, i, clamp(sampleAt(format, frames, i)*gain, -1, 1))
+	}
+}
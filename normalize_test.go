@@ -0,0 +1,48 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestNormalizePeak(t *testing.T) {
+	frames := make([]byte, 4*2)
+	for i, v := range []int16{1000, -4000, 2000, 0} {
+		binary.LittleEndian.PutUint16(frames[i*2:], uint16(v))
+	}
+
+	err := malgo.NormalizePeak(malgo.FormatS16, frames, 2, 2, -6)
+	assertNil(t, err, "No error expected normalizing")
+
+	peak := int16(binary.LittleEndian.Uint16(frames[2:]))
+	expected := -32768 * math.Pow(10, -6.0/20)
+	if math.Abs(float64(peak)-expected) > 1 {
+		t.Fatalf("expected peak %.0f, got %d", expected, peak)
+	}
+	assertEqual(t, int16(8211), int16(binary.LittleEndian.Uint16(frames[4:])), "Expected the same gain on every sample")
+
+	silence := make([]byte, 8)
+	err = malgo.NormalizePeak(malgo.FormatF32, silence, 2, 1, 0)
+	assertNil(t, err, "No error expected normalizing silence")
+	assertEqual(t, string(make([]byte, 8)), string(silence), "Expected silence to be unchanged")
+
+	err = malgo.NormalizePeak(malgo.FormatS16, frames, 3, 2, 0)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a short buffer")
+}
+
+func TestNormalizeLUFS(t *testing.T) {
+	const sampleRate = 48000
+
+	frames := sineF32(997, 0.01, sampleRate, sampleRate*2, 1)
+	err := malgo.NormalizeLUFS(malgo.FormatF32, frames, sampleRate*2, 1, sampleRate, -23)
+	assertNil(t, err, "No error expected normalizing")
+
+	lufs, err := malgo.MeasureLUFS(malgo.FormatF32, frames, sampleRate*2, 1, sampleRate)
+	assertNil(t, err, "No error expected measuring loudness")
+	if math.Abs(lufs+23) > 0.1 {
+		t.Fatalf("expected -23 LUFS after normalizing, got %.2f", lufs)
+	}
+}
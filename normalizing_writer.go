@@ -0,0 +1,146 @@
+package malgo
+
+import (
+	"io"
+	"math"
+)
+
+// Limits of the streaming normalization of NormalizingWriter.
+const (
+	normalizeGateLUFS    = -70  // Blocks quieter than this, such as silence, keep the previous gain.
+	normalizeMaxBoostDB  = 24   // Largest gain applied to quiet material.
+	normalizeSmoothingMs = 1000 // Time constant of the gain changes.
+)
+
+type normalizingWriter struct {
+	dst        io.Writer
+	format     DataFormat
+	target     float64
+	meter      *LoudnessMeter
+	err        error
+	frameSize  int
+	blockSize  int
+	lookahead  int
+	coeff      float64
+	gain       float64
+	targetGain float64
+	pending    []byte
+	delay      []byte
+	out        []byte
+	closed     bool
+}
+
+// NewNormalizingWriter returns a writer that levels interleaved frames of the given format to
+// targetLUFS and writes them to dst.
+//
+// The loudness is measured with a LoudnessMeter over the last 400 ms and the gain moves smoothly
+// towards the one that brings it to the target, boosting by at most 24 dB and holding during silence.
+// The frames are delayed by lookaheadMs, so the gain reacts to loudness changes before they are
+// written; this delay is the latency of the writer. As the gain follows the material, the result only
+// approximates the integrated loudness an offline two-pass NormalizeLUFS would reach. Samples pushed
+// beyond full scale are clipped.
+//
+// Partial frames are kept until they are completed by the next Write. Close writes the frames held for
+// the lookahead; it does not close dst. Invalid arguments are reported by Write and Close.
+func NewNormalizingWriter(dst io.Writer, format DataFormat, targetLUFS float64, lookaheadMs int) io.WriteCloser {
+	w := &normalizingWriter{
+		dst:        dst,
+		format:     format,
+		target:     targetLUFS,
+		frameSize:  FrameSizeInBytes(format.Format, int(format.Channels)),
+		gain:       1,
+		targetGain: 1,
+	}
+	if dst == nil || w.frameSize == 0 || format.SampleRate == 0 || lookaheadMs < 0 {
+		w.err = ErrInvalidArgs
+		return w
+	}
+
+	w.meter, w.err = NewLoudnessMeter(int(format.Channels), int(format.SampleRate))
+	w.blockSize = (int(format.SampleRate)/100 + 1) * w.frameSize
+	w.lookahead = lookaheadMs * int(format.SampleRate) / 1000 * w.frameSize
+	w.coeff = timeConstantCoeff(normalizeSmoothingMs, int(format.SampleRate))
+	return w
+}
+
+func (w *normalizingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, ErrInvalidOperation
+	}
+
+	// p is accepted as a whole, so a failing block is not metered and written again by a later call;
+	// the error is kept and returned by every following Write and Close.
+	w.pending = append(w.pending, p...)
+	offset := 0
+	for len(w.pending)-offset >= w.blockSize {
+		if err := w.processBlock(w.pending[offset : offset+w.blockSize]); err != nil {
+			w.err = err
+			break
+		}
+		offset += w.blockSize
+	}
+	w.pending = append(w.pending[:0], w.pending[offset:]...)
+	return len(p), w.err
+}
+
+// processBlock meters a block of frames and writes the frames that leave the lookahead.
+func (w *normalizingWriter) processBlock(block []byte) error {
+	if err := w.meter.Process(w.format.Format, block, len(block)/w.frameSize); err != nil {
+		return err
+	}
+	if lufs := w.meter.MomentaryLUFS(); lufs > normalizeGateLUFS {
+		w.targetGain = dbToLinear(math.Min(w.target-lufs, normalizeMaxBoostDB))
+	}
+
+	w.delay = append(w.delay, block...)
+	if ready := len(w.delay) - w.lookahead; ready > 0 {
+		if err := w.emit(w.delay[:ready]); err != nil {
+			return err
+		}
+		w.delay = append(w.delay[:0], w.delay[ready:]...)
+	}
+	return nil
+}
+
+// emit applies the smoothed gain to whole frames and writes them to dst.
+func (w *normalizingWriter) emit(frames []byte) error {
+	w.out = append(w.out[:0], frames...)
+	channels := int(w.format.Channels)
+	for frame := 0; frame < len(w.out)/w.frameSize; frame++ {
+		w.gain = w.targetGain + (w.gain-w.targetGain)*w.coeff
+		for i := frame * channels; i < (frame+1)*channels; i++ {
+			setSampleAt(w.format.Format, w.out, i, clamp(sampleAt(w.format.Format, w.out, i)*w.gain, -1, 1))
+		}
+	}
+	_, err := w.dst.Write(w.out)
+	return err
+}
+
+// Close writes the frames held for the lookahead, including an incomplete block. Bytes of an
+// incomplete frame are dropped.
+func (w *normalizingWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if whole := len(w.pending) / w.frameSize * w.frameSize; whole > 0 {
+		if err := w.meter.Process(w.format.Format, w.pending[:whole], whole/w.frameSize); err != nil {
+			return err
+		}
+		w.delay = append(w.delay, w.pending[:whole]...)
+	}
+	w.pending = nil
+	if len(w.delay) == 0 {
+		return nil
+	}
+	w.err = w.emit(w.delay)
+	w.delay = nil
+	return w.err
+}
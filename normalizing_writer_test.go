@@ -0,0 +1,89 @@
+package malgo_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestNormalizingWriter(t *testing.T) {
+	const sampleRate = 48000
+	format := malgo.DataFormat{Format: malgo.FormatF32, Channels: 2, SampleRate: sampleRate}
+
+	in := sineF32(997, 0.02, sampleRate, sampleRate*10, 2)
+	var out bytes.Buffer
+	w := malgo.NewNormalizingWriter(&out, format, -23, 500)
+
+	// Chunks that do not end on frame boundaries.
+	for data := in; len(data) > 0; {
+		n := 1234
+		if n > len(data) {
+			n = len(data)
+		}
+		written, err := w.Write(data[:n])
+		assertNil(t, err, "No error expected writing")
+		assertEqual(t, n, written, "Expected the whole chunk to be accepted")
+		data = data[n:]
+	}
+	assertTrue(t, out.Len() < len(in), "Expected the lookahead to hold frames back")
+	assertNil(t, w.Close(), "No error expected closing")
+	assertEqual(t, len(in), out.Len(), "Expected every frame after Close")
+
+	before, err := malgo.MeasureLUFS(malgo.FormatF32, in, sampleRate*10, 2, sampleRate)
+	assertNil(t, err, "No error expected measuring loudness")
+	tail := out.Bytes()[len(in)/2:]
+	after, err := malgo.MeasureLUFS(malgo.FormatF32, tail, sampleRate*5, 2, sampleRate)
+	assertNil(t, err, "No error expected measuring loudness")
+	if before > -30 || math.Abs(after+23) > 0.5 {
+		t.Fatalf("expected %.2f LUFS to be leveled to -23 LUFS, got %.2f", before, after)
+	}
+
+	_, err = w.Write(in[:8])
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error writing after Close")
+
+	var silent bytes.Buffer
+	w = malgo.NewNormalizingWriter(&silent, format, -23, 0)
+	_, err = w.Write(make([]byte, sampleRate*8))
+	assertNil(t, err, "No error expected writing silence")
+	assertNil(t, w.Close(), "No error expected closing")
+	assertTrue(t, bytes.Equal(make([]byte, sampleRate*8), silent.Bytes()), "Expected silence to stay silent")
+
+	_, err = malgo.NewNormalizingWriter(&out, malgo.DataFormat{Format: malgo.FormatF32}, -23, 0).Write(in)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for a format without channels")
+}
+
+// failingWriter accepts a number of writes and then fails.
+type failingWriter struct {
+	writes int
+	limit  int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == w.limit {
+		return 0, errors.New("write failed")
+	}
+	w.writes++
+	return len(p), nil
+}
+
+func TestNormalizingWriterError(t *testing.T) {
+	const sampleRate = 48000
+	dst := &failingWriter{limit: 1}
+	w := malgo.NewNormalizingWriter(dst, malgo.DataFormat{Format: malgo.FormatF32, Channels: 1, SampleRate: sampleRate}, -23, 0)
+
+	// Ten blocks of 10 ms; the second block fails to be written.
+	frames := sineF32(1000, 0.1, sampleRate, sampleRate/10, 1)
+	n, err := w.Write(frames)
+	assertNotNil(t, err, "Expected the error of dst")
+	assertEqual(t, len(frames), n, "Expected the whole buffer to be accepted")
+	assertEqual(t, 1, dst.writes, "Expected writing to stop at the error")
+
+	dst.limit = 100
+	_, err2 := w.Write(frames)
+	assertEqual(t, err, err2, "Expected the error to be kept")
+	assertEqual(t, err, w.Close(), "Expected Close to return the error")
+	assertEqual(t, 1, dst.writes, "Expected no block to be written again")
+}
@@ -0,0 +1,104 @@
+package malgo
+
+// #include "malgo.h"
+import "C"
+
+// DeviceNotificationType type.
+type DeviceNotificationType uint32
+
+// DeviceNotificationType enumeration.
+const (
+	NotificationStarted DeviceNotificationType = iota
+	NotificationStopped
+	NotificationRerouted
+	NotificationInterruptionBegan
+	NotificationInterruptionEnded
+	NotificationUnlocked
+
+	// NotificationFormatChanged follows NotificationRerouted when the native format of the device changed.
+	NotificationFormatChanged
+)
+
+// DeviceNotification describes a change in the state of a device.
+type DeviceNotification struct {
+	Type DeviceNotificationType
+
+	// DeviceType and Format are set for NotificationFormatChanged: the direction, Playback or Capture,
+	// whose native device format changed, and the new native format.
+	DeviceType DeviceType
+	Format     DataFormat
+}
+
+// NotificationProc type.
+//
+// miniaudio keeps the format, channel count and sample rate seen by the data callback when the device
+// is rerouted, for example when a Bluetooth headset switches profiles, and converts from the new native
+// format instead. NotificationFormatChanged tells the application that this conversion now takes place,
+// so it can reinitialize the device with the new native format to avoid it.
+type NotificationProc func(notification DeviceNotification)
+
+// nativeFormats holds the native format of the playback and capture sides of a device, in that order.
+// The format of an unused side is the zero value.
+type nativeFormats [2]DataFormat
+
+// formatChanges returns a NotificationFormatChanged for every side whose native format differs.
+func (previous nativeFormats) formatChanges(current nativeFormats) []DeviceNotification {
+	var changes []DeviceNotification
+	for i, kind := range []DeviceType{Playback, Capture} {
+		if current[i] != previous[i] {
+			changes = append(changes, DeviceNotification{Type: NotificationFormatChanged, DeviceType: kind, Format: current[i]})
+		}
+	}
+	return changes
+}
+
+func deviceNativeFormats(pDevice *C.ma_device) nativeFormats {
+	var formats nativeFormats
+	deviceType := DeviceType(pDevice._type)
+	if deviceType == Playback || deviceType == Duplex {
+		formats[0] = DataFormat{
+			Format:     FormatType(pDevice.playback.internalFormat),
+			Channels:   uint32(pDevice.playback.internalChannels),
+			SampleRate: uint32(pDevice.playback.internalSampleRate),
+		}
+	}
+	if deviceType != Playback {
+		formats[1] = DataFormat{
+			Format:     FormatType(pDevice.capture.internalFormat),
+			Channels:   uint32(pDevice.capture.internalChannels),
+			SampleRate: uint32(pDevice.capture.internalSampleRate),
+		}
+	}
+	return formats
+}
+
+var notificationCallbacks = make(map[*C.ma_device]NotificationProc)
+var deviceFormats = make(map[*C.ma_device]nativeFormats)
+
+//export goNotificationCallback
+func goNotificationCallback(pNotification *C.ma_device_notification) {
+	pDevice := pNotification.pDevice
+	defer func() {
+		if r := recover(); r != nil {
+			recoverCallback(pDevice, r)
+		}
+	}()
+
+	notificationType := DeviceNotificationType(pNotification._type)
+	deviceMutex.Lock()
+	callback := notificationCallbacks[pDevice]
+	var changes []DeviceNotification
+	if notificationType == NotificationRerouted {
+		current := deviceNativeFormats(pDevice)
+		changes = deviceFormats[pDevice].formatChanges(current)
+		deviceFormats[pDevice] = current
+	}
+	deviceMutex.Unlock()
+
+	if callback != nil {
+		callback(DeviceNotification{Type: notificationType})
+		for _, change := range changes {
+			callback(change)
+		}
+	}
+}
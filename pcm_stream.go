@@ -0,0 +1,150 @@
+package malgo
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// pcmStreamMagic starts every packet of a PCM stream.
+var pcmStreamMagic = [4]byte{'M', 'P', 'C', 'M'}
+
+// pcmStreamHeaderSize is the size of the magic followed by the format, channel count, sample rate
+// and frame count as little-endian uint32 values.
+const pcmStreamHeaderSize = 20
+
+type pcmStreamWriter struct {
+	conn      net.Conn
+	format    DataFormat
+	frameSize int
+	pending   []byte
+	packet    []byte
+}
+
+// NewPCMStreamWriter returns a writer that sends interleaved frames of the given format over conn.
+//
+// Every Write is sent as one packet made of a header, describing the format, channel count, sample
+// rate and frame count, followed by the frames. Bytes of a trailing partial frame are kept and sent
+// with the next Write, so the packets always hold whole frames.
+func NewPCMStreamWriter(conn net.Conn, format DataFormat) io.Writer {
+	return &pcmStreamWriter{
+		conn:      conn,
+		format:    format,
+		frameSize: FrameSizeInBytes(format.Format, int(format.Channels)),
+	}
+}
+
+func (w *pcmStreamWriter) Write(p []byte) (int, error) {
+	if w.frameSize == 0 {
+		return 0, ErrInvalidArgs
+	}
+
+	w.pending = append(w.pending, p...)
+	frameCount := len(w.pending) / w.frameSize
+	if frameCount == 0 {
+		return len(p), nil
+	}
+
+	size := frameCount * w.frameSize
+	var header [pcmStreamHeaderSize]byte
+	copy(header[:], pcmStreamMagic[:])
+	binary.LittleEndian.PutUint32(header[4:], uint32(w.format.Format))
+	binary.LittleEndian.PutUint32(header[8:], w.format.Channels)
+	binary.LittleEndian.PutUint32(header[12:], w.format.SampleRate)
+	binary.LittleEndian.PutUint32(header[16:], uint32(frameCount))
+	w.packet = append(append(w.packet[:0], header[:]...), w.pending[:size]...)
+	if _, err := w.conn.Write(w.packet); err != nil {
+		w.pending = w.pending[:len(w.pending)-len(p)]
+		return 0, err
+	}
+
+	w.pending = append(w.pending[:0], w.pending[size:]...)
+	return len(p), nil
+}
+
+type pcmStreamReader struct {
+	conn      net.Conn
+	format    DataFormat
+	frameSize int
+	remaining int
+	announced bool // The format is taken from the first packet instead of being given.
+}
+
+// NewPCMStreamReader returns a reader for frames sent by a writer from NewPCMStreamWriter.
+//
+// Read only returns whole frames: it fails with io.ErrShortBuffer if p cannot hold one frame, and
+// blocks until the frames it returns have fully arrived. ErrFormatNotSupported is returned if a packet
+// announces a different format, channel count or sample rate, and ErrInvalidData if the stream is
+// out of sync. Use NewAnnouncedPCMStreamReader to learn the format from the stream instead.
+func NewPCMStreamReader(conn net.Conn, format DataFormat) io.Reader {
+	return &pcmStreamReader{
+		conn:      conn,
+		format:    format,
+		frameSize: FrameSizeInBytes(format.Format, int(format.Channels)),
+	}
+}
+
+// NewAnnouncedPCMStreamReader returns a reader for frames sent by a writer from NewPCMStreamWriter,
+// along with the format announced by the stream. It blocks until the header of the first packet has
+// arrived. Later packets must announce the same format, as with NewPCMStreamReader, and
+// ErrInvalidData is returned if the first one announces a format with no frame size.
+func NewAnnouncedPCMStreamReader(conn net.Conn) (io.Reader, DataFormat, error) {
+	r := &pcmStreamReader{conn: conn, announced: true}
+	if err := r.readHeader(); err != nil {
+		return nil, DataFormat{}, err
+	}
+	return r, r.format, nil
+}
+
+func (r *pcmStreamReader) Read(p []byte) (int, error) {
+	if r.frameSize == 0 {
+		return 0, ErrInvalidArgs
+	}
+	if len(p) < r.frameSize {
+		return 0, io.ErrShortBuffer
+	}
+
+	for r.remaining == 0 {
+		if err := r.readHeader(); err != nil {
+			return 0, err
+		}
+	}
+
+	frameCount := len(p) / r.frameSize
+	if frameCount > r.remaining {
+		frameCount = r.remaining
+	}
+	n, err := io.ReadFull(r.conn, p[:frameCount*r.frameSize])
+	r.remaining -= n / r.frameSize
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *pcmStreamReader) readHeader() error {
+	var header [pcmStreamHeaderSize]byte
+	if _, err := io.ReadFull(r.conn, header[:]); err != nil {
+		return err
+	}
+	if [4]byte{header[0], header[1], header[2], header[3]} != pcmStreamMagic {
+		return ErrInvalidData
+	}
+
+	format := FormatType(binary.LittleEndian.Uint32(header[4:]))
+	channels := binary.LittleEndian.Uint32(header[8:])
+	sampleRate := binary.LittleEndian.Uint32(header[12:])
+	if r.announced && r.frameSize == 0 {
+		r.format = DataFormat{Format: format, Channels: channels, SampleRate: sampleRate}
+		r.frameSize = FrameSizeInBytes(format, int(channels))
+		if r.frameSize == 0 {
+			return ErrInvalidData
+		}
+	}
+	if format != r.format.Format || channels != r.format.Channels || sampleRate != r.format.SampleRate {
+		return ErrFormatNotSupported
+	}
+
+	r.remaining = int(binary.LittleEndian.Uint32(header[16:]))
+	return nil
+}
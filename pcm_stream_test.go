@@ -0,0 +1,97 @@
+package malgo_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestPCMStream(t *testing.T) {
+	format := malgo.DataFormat{Format: malgo.FormatS16, Channels: 2, SampleRate: 48000}
+	client, server := net.Pipe()
+	defer server.Close()
+
+	frames := make([]byte, 100*4)
+	for i := range frames {
+		frames[i] = byte(i)
+	}
+
+	go func() {
+		defer client.Close()
+		writer := malgo.NewPCMStreamWriter(client, format)
+		// Split in the middle of a frame.
+		if _, err := writer.Write(frames[:150]); err != nil {
+			t.Error(err)
+		}
+		if _, err := writer.Write(frames[150:]); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	reader := malgo.NewPCMStreamReader(server, format)
+	var received []byte
+	buf := make([]byte, 30)
+	for {
+		n, err := reader.Read(buf)
+		if n%4 != 0 {
+			t.Fatalf("expected whole frames, got %d bytes", n)
+		}
+		received = append(received, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		assertNil(t, err, "No error expected reading frames")
+	}
+	assertTrue(t, bytes.Equal(frames, received), "Expected identical frames")
+
+	_, err := reader.Read(make([]byte, 3))
+	assertEqual(t, io.ErrShortBuffer, err, "Expected error for a buffer smaller than a frame")
+}
+
+func TestPCMStreamFormatMismatch(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	go func() {
+		defer client.Close()
+		writer := malgo.NewPCMStreamWriter(client, malgo.DataFormat{Format: malgo.FormatF32, Channels: 2, SampleRate: 48000})
+		_, _ = writer.Write(make([]byte, 8))
+	}()
+
+	reader := malgo.NewPCMStreamReader(server, malgo.DataFormat{Format: malgo.FormatS16, Channels: 2, SampleRate: 48000})
+	_, err := reader.Read(make([]byte, 64))
+	assertEqual(t, malgo.ErrFormatNotSupported, err, "Expected error for a different format")
+}
+
+func TestPCMStreamAnnouncedFormat(t *testing.T) {
+	format := malgo.DataFormat{Format: malgo.FormatS24, Channels: 3, SampleRate: 96000}
+	client, server := net.Pipe()
+	defer server.Close()
+
+	frames := make([]byte, 10*9)
+	for i := range frames {
+		frames[i] = byte(i)
+	}
+
+	go func() {
+		defer client.Close()
+		writer := malgo.NewPCMStreamWriter(client, format)
+		if _, err := writer.Write(frames[:45]); err != nil {
+			t.Error(err)
+		}
+		if _, err := writer.Write(frames[45:]); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	reader, announced, err := malgo.NewAnnouncedPCMStreamReader(server)
+	assertNil(t, err, "No error expected reading the announced format")
+	assertEqual(t, format, announced, "Expected the format announced by the writer")
+
+	received, err := io.ReadAll(reader)
+	assertNil(t, err, "No error expected reading frames")
+	assertTrue(t, bytes.Equal(frames, received), "Expected identical frames")
+}
@@ -0,0 +1,127 @@
+package malgo
+
+import (
+	"math"
+)
+
+// SamplePeak returns the highest absolute sample of every channel of interleaved frames, in dBFS.
+// Silent channels are negative infinity. Returns nil for an unknown format or a short buffer.
+func SamplePeak(format FormatType, frames []byte, frameCount, channels int) []float32 {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount < 0 || len(frames) < frameCount*channels*sampleSize {
+		return nil
+	}
+
+	peaks := make([]float32, channels)
+	for channel := range peaks {
+		peak := 0.0
+		for i := 0; i < frameCount; i++ {
+			peak = math.Max(peak, math.Abs(sampleAt(format, frames, i*channels+channel)))
+		}
+		peaks[channel] = float32(linearToDB(peak))
+	}
+	return peaks
+}
+
+// truePeakTaps is the number of input samples on each side of an interpolated position used by TruePeak.
+const truePeakTaps = 16
+
+// TruePeak returns the true peak of every channel of interleaved frames, in dBTP.
+//
+// Each channel is oversampled by the given factor with a Blackman windowed sinc interpolator, so peaks
+// falling between samples, which SamplePeak misses, are caught. ITU-R BS.1770 asks for at least 4 times
+// oversampling at 48 kHz, so lower sample rates need a higher factor for the same accuracy; sampleRate is
+// otherwise only validated. An oversample of 1 gives the sample peak. Returns nil for invalid arguments.
+//
+// The miniaudio resampler is not used because its linear interpolation attenuates high frequencies,
+// by close to 2 dB at a quarter of the sample rate, and would under-report the peaks it is meant to find.
+func TruePeak(format FormatType, frames []byte, frameCount, channels, sampleRate int, oversample int) []float32 {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount < 0 || len(frames) < frameCount*channels*sampleSize ||
+		sampleRate <= 0 || oversample < 1 {
+		return nil
+	}
+
+	// phases[p-1] holds the interpolation coefficients for the position p/oversample past a sample.
+	phases := make([][2 * truePeakTaps]float64, oversample-1)
+	for p := range phases {
+		offset := float64(p+1) / float64(oversample)
+		for j := range phases[p] {
+			x := offset - float64(j-truePeakTaps+1)
+			phases[p][j] = sinc(x) * blackman(x/truePeakTaps)
+		}
+	}
+
+	peaks := make([]float32, channels)
+	for channel := range peaks {
+		sample := func(i int) float64 {
+			if i < 0 || i >= frameCount {
+				return 0
+			}
+			return sampleAt(format, frames, i*channels+channel)
+		}
+
+		peak := 0.0
+		for i := -1; i < frameCount; i++ {
+			peak = math.Max(peak, math.Abs(sample(i)))
+			for _, coefficients := range phases {
+				v := 0.0
+				for j, c := range coefficients {
+					v += c * sample(i+j-truePeakTaps+1)
+				}
+				peak = math.Max(peak, math.Abs(v))
+			}
+		}
+		peaks[channel] = float32(linearToDB(peak))
+	}
+	return peaks
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over [-1, 1], zero outside.
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+// CountClippedSamples returns how many of the first sampleCount samples sit at the minimum or maximum
+// value of an integer format, or have an absolute value of 1 or more in FormatF32. Samples past the end
+// of frames are not counted, and an unknown format counts none.
+func CountClippedSamples(format FormatType, frames []byte, sampleCount int) int {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 {
+		return 0
+	}
+	if available := len(frames) / sampleSize; sampleCount > available {
+		sampleCount = available
+	}
+
+	// The lowest value of the integer formats normalizes to -1, the highest to just below 1.
+	low, high := -1.0, 1.0
+	switch format {
+	case FormatU8:
+		high = 127.0 / 128
+	case FormatS16:
+		high = 32767.0 / 32768
+	case FormatS24:
+		high = 8388607.0 / 8388608
+	case FormatS32:
+		high = 2147483647.0 / 2147483648
+	}
+
+	count := 0
+	for i := 0; i < sampleCount; i++ {
+		if v := sampleAt(format, frames, i); v <= low || v >= high {
+			count++
+		}
+	}
+	return count
+}
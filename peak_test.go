@@ -0,0 +1,70 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestSamplePeak(t *testing.T) {
+	frames := make([]byte, 4*2*2)
+	setF32(frames, 0, 0.5)
+	setF32(frames, 2, -0.25)
+
+	peaks := malgo.SamplePeak(malgo.FormatF32, frames, 2, 2)
+	assertEqual(t, 2, len(peaks), "Expected one peak per channel")
+	if math.Abs(float64(peaks[0])+6.02) > 0.01 {
+		t.Fatalf("expected -6.02 dBFS, got %.2f", peaks[0])
+	}
+	assertTrue(t, math.IsInf(float64(peaks[1]), -1), "Expected a silent channel to be negative infinity")
+
+	assertTrue(t, malgo.SamplePeak(malgo.FormatF32, frames, 3, 2) == nil, "Expected nil for a short buffer")
+}
+
+func TestTruePeak(t *testing.T) {
+	const sampleRate = 48000
+
+	// A sine at a quarter of the sample rate whose samples all fall 3 dB below its peak.
+	frames := make([]byte, sampleRate*4)
+	for i := 0; i < sampleRate; i++ {
+		setF32(frames, i, float32(0.5*math.Sin(math.Pi*float64(i)/2+math.Pi/4)))
+	}
+
+	samplePeak := malgo.SamplePeak(malgo.FormatF32, frames, sampleRate, 1)
+	if math.Abs(float64(samplePeak[0])+9.03) > 0.01 {
+		t.Fatalf("expected a sample peak of -9.03 dBFS, got %.2f", samplePeak[0])
+	}
+
+	truePeak := malgo.TruePeak(malgo.FormatF32, frames, sampleRate, 1, sampleRate, 4)
+	if math.Abs(float64(truePeak[0])+6.02) > 0.2 {
+		t.Fatalf("expected a true peak of -6.02 dBTP, got %.2f", truePeak[0])
+	}
+
+	assertEqual(t, samplePeak[0], malgo.TruePeak(malgo.FormatF32, frames, sampleRate, 1, sampleRate, 1)[0], "Expected no oversampling to give the sample peak")
+	assertTrue(t, malgo.TruePeak(malgo.FormatF32, frames, sampleRate, 1, sampleRate, 0) == nil, "Expected nil for an invalid factor")
+}
+
+func setF32(frames []byte, index int, v float32) {
+	binary.LittleEndian.PutUint32(frames[index*4:], math.Float32bits(v))
+}
+
+func TestCountClippedSamples(t *testing.T) {
+	s16 := make([]byte, 5*2)
+	for i, v := range []int16{32767, -32768, 32766, 0, -32767} {
+		binary.LittleEndian.PutUint16(s16[i*2:], uint16(v))
+	}
+	assertEqual(t, 2, malgo.CountClippedSamples(malgo.FormatS16, s16, 5), "Expected the extreme values to be counted")
+	assertEqual(t, 1, malgo.CountClippedSamples(malgo.FormatS16, s16, 1), "Expected only the first samples to be counted")
+	assertEqual(t, 2, malgo.CountClippedSamples(malgo.FormatS16, s16, 100), "Expected samples past the buffer to be ignored")
+
+	assertEqual(t, 2, malgo.CountClippedSamples(malgo.FormatU8, []byte{0, 1, 128, 254, 255}, 5), "Unexpected U8 count")
+	assertEqual(t, 2, malgo.CountClippedSamples(malgo.FormatS24, []byte{0xff, 0xff, 0x7f, 0x00, 0x00, 0x80, 0xfe, 0xff, 0x7f}, 3), "Unexpected S24 count")
+
+	f32 := make([]byte, 4*4)
+	for i, v := range []float32{1, -1.5, 0.999, -0.5} {
+		setF32(f32, i, v)
+	}
+	assertEqual(t, 2, malgo.CountClippedSamples(malgo.FormatF32, f32, 4), "Expected samples at or beyond full scale to be counted")
+}
@@ -0,0 +1,117 @@
+package malgo
+
+// Stage is a processing block that can be chained in a Pipeline. It reads frameCount interleaved
+// frames from in and writes the same number of frames to out.
+//
+// Converter and Compressor implement Stage.
+type Stage interface {
+	ProcessPCMFrames(out, in []byte, frameCount int) error
+}
+
+// latencyReporter is implemented by stages that know the delay they add to the signal.
+type latencyReporter interface {
+	LatencyInFrames() int
+}
+
+// outputFrameSizer is implemented by stages that know the size of the frames they write.
+type outputFrameSizer interface {
+	outputFrameSize() int
+}
+
+// Pipeline runs frames through a chain of stages with a single call.
+//
+// Intermediate results are kept in scratch buffers owned by the pipeline, which grow to the largest
+// frame count processed and are reused afterwards. A Pipeline must not be used concurrently.
+type Pipeline struct {
+	stages  []Stage
+	scratch [2][]byte
+}
+
+// NewPipeline creates a pipeline that runs the stages in the given order.
+func NewPipeline(stages ...Stage) (*Pipeline, error) {
+	if len(stages) == 0 {
+		return nil, ErrInvalidArgs
+	}
+	for _, stage := range stages {
+		if stage == nil {
+			return nil, ErrInvalidArgs
+		}
+	}
+
+	return &Pipeline{stages: append([]Stage(nil), stages...)}, nil
+}
+
+// Process runs frameCount frames from in through every stage and writes the result of the last stage to out.
+//
+// The layout of in must match the input of the first stage and out must fit the output of the last.
+// Intermediate buffers are sized from the stage's output format for Converter and Compressor, and
+// from the size of out for other stages.
+func (p *Pipeline) Process(out, in []byte, frameCount int) error {
+	if frameCount < 0 {
+		return ErrInvalidArgs
+	}
+
+	src := in
+	for i, stage := range p.stages {
+		dst := out
+		if i < len(p.stages)-1 {
+			dst = p.scratchBuffer(i%2, frameCount*p.frameSize(stage, out, frameCount))
+		}
+		if err := stage.ProcessPCMFrames(dst, src, frameCount); err != nil {
+			return err
+		}
+		src = dst
+	}
+
+	return nil
+}
+
+// ProcessPCMFrames runs the pipeline, which lets a pipeline be used as a stage of another one.
+func (p *Pipeline) ProcessPCMFrames(out, in []byte, frameCount int) error {
+	return p.Process(out, in, frameCount)
+}
+
+// TotalLatencyInFrames returns the sum of the delays of the stages, in frames, which is the offset of
+// the output relative to the input. Stages without a LatencyInFrames method count as zero.
+//
+// A Converter in a pipeline does not change the sample rate, so all stages report frames of the same rate.
+func (p *Pipeline) TotalLatencyInFrames() int {
+	total := 0
+	for _, stage := range p.stages {
+		if reporter, ok := stage.(latencyReporter); ok {
+			total += reporter.LatencyInFrames()
+		}
+	}
+	return total
+}
+
+// LatencyInFrames returns TotalLatencyInFrames, which lets the latency of a nested pipeline be counted.
+func (p *Pipeline) LatencyInFrames() int {
+	return p.TotalLatencyInFrames()
+}
+
+func (p *Pipeline) outputFrameSize() int {
+	if sizer, ok := p.stages[len(p.stages)-1].(outputFrameSizer); ok {
+		return sizer.outputFrameSize()
+	}
+	return 0
+}
+
+func (p *Pipeline) frameSize(stage Stage, out []byte, frameCount int) int {
+	if sizer, ok := stage.(outputFrameSizer); ok {
+		if size := sizer.outputFrameSize(); size > 0 {
+			return size
+		}
+	}
+	if frameCount == 0 {
+		return 0
+	}
+	return len(out) / frameCount
+}
+
+func (p *Pipeline) scratchBuffer(index, size int) []byte {
+	if cap(p.scratch[index]) < size {
+		p.scratch[index] = make([]byte, size)
+	}
+	return p.scratch[index][:size]
+}
@@ -0,0 +1,115 @@
+package malgo_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestPipeline(t *testing.T) {
+	toFloat, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer toFloat.Uninit()
+
+	compressor, err := malgo.NewCompressor(malgo.CompressorConfig{
+		Channels:     2,
+		SampleRate:   48000,
+		Ratio:        1,
+		MakeupGainDB: -6.0206,
+	})
+	assertNil(t, err, "No error expected creating compressor")
+
+	toMono, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatS16,
+		ChannelsIn:    2,
+		ChannelsOut:   1,
+		SampleRateIn:  48000,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer toMono.Uninit()
+
+	pipeline, err := malgo.NewPipeline(toFloat, compressor, toMono)
+	assertNil(t, err, "No error expected creating pipeline")
+
+	const frameCount = 64
+	in := make([]byte, frameCount*4)
+	for i := 0; i < frameCount*2; i++ {
+		binary.LittleEndian.PutUint16(in[i*2:], uint16(int16(16000)))
+	}
+	out := make([]byte, frameCount*2)
+
+	err = pipeline.Process(out, in, frameCount)
+	assertNil(t, err, "No error expected processing frames")
+	for i := 0; i < frameCount; i++ {
+		sample := int16(binary.LittleEndian.Uint16(out[i*2:]))
+		if sample < 7990 || sample > 8010 {
+			t.Fatalf("frame %d: expected 8000, got %d", i, sample)
+		}
+	}
+
+	_, err = malgo.NewPipeline()
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for an empty pipeline")
+}
+
+func TestPipelineRejectsResampling(t *testing.T) {
+	resampler, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  44100,
+		SampleRateOut: 48000,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer resampler.Uninit()
+
+	pipeline, err := malgo.NewPipeline(resampler)
+	assertNil(t, err, "No error expected creating pipeline")
+
+	err = pipeline.Process(make([]byte, 64*4), make([]byte, 64*4), 64)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error for a resampling stage")
+}
+
+func TestPipelineTotalLatency(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:               malgo.FormatF32,
+		FormatOut:              malgo.FormatF32,
+		ChannelsIn:             2,
+		ChannelsOut:            2,
+		SampleRateIn:           48000,
+		SampleRateOut:          48000,
+		AllowDynamicSampleRate: true,
+		Resampling:             malgo.ResampleConfig{Algorithm: malgo.ResampleAlgorithmLinear, Linear: malgo.ResampleLinearConfig{LpfOrder: 4}},
+	}
+	resampler, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer resampler.Uninit()
+	assertTrue(t, resampler.LatencyInFrames() > 0, "Expected the resampler to add latency")
+
+	config.AllowDynamicSampleRate = false
+	passthrough, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	defer passthrough.Uninit()
+	assertEqual(t, 0, passthrough.LatencyInFrames(), "Expected no latency without resampling")
+
+	compressor, err := malgo.NewCompressor(malgo.CompressorConfig{Channels: 2, SampleRate: 48000, Ratio: 4})
+	assertNil(t, err, "No error expected creating compressor")
+
+	inner, err := malgo.NewPipeline(resampler, compressor)
+	assertNil(t, err, "No error expected creating pipeline")
+	assertEqual(t, resampler.LatencyInFrames(), inner.TotalLatencyInFrames(), "Unexpected pipeline latency")
+
+	outer, err := malgo.NewPipeline(inner, passthrough, resampler)
+	assertNil(t, err, "No error expected creating pipeline")
+	assertEqual(t, 2*resampler.LatencyInFrames(), outer.TotalLatencyInFrames(), "Expected nested latency to be counted")
+}
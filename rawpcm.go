@@ -0,0 +1,191 @@
+package malgo
+
+import (
+	"io"
+	"os"
+)
+
+// WriteRawPCM writes interleaved frames to a file as headerless PCM, replacing any existing file.
+// The length of frames must be a whole number of frames of the given format, or a *FrameAlignmentError
+// is returned.
+func WriteRawPCM(path string, frames []byte, format DataFormat) error {
+	if _, err := WholeFrameCount(format.Format, int(format.Channels), frames); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, frames, 0644)
+}
+
+// ReadRawPCM reads a headerless PCM file holding interleaved frames of the given format.
+// A *FrameAlignmentError is returned if the file size is not a whole number of frames.
+func ReadRawPCM(path string, format DataFormat) ([]byte, error) {
+	if FrameSizeInBytes(format.Format, int(format.Channels)) == 0 {
+		return nil, ErrInvalidArgs
+	}
+
+	frames, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := WholeFrameCount(format.Format, int(format.Channels), frames); err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+// processFileChunkFrames is the number of input frames ProcessFile converts at a time.
+const processFileChunkFrames = 4096
+
+// ProcessFile converts a headerless PCM file of the input format of config into a headerless PCM file
+// of its output format, replacing any existing output file.
+//
+// The input is memory mapped where the platform supports it, and read in chunks otherwise; the output
+// is written as it is converted. Either way the frames go through the converter a few thousand at a
+// time, so memory use does not depend on the file size. The delay of the resampler is removed from
+// the start of the output, and at the end of the input the resampler is flushed with silence, so the
+// output is time aligned with the input and holds the number of frames the rate change implies.
+//
+// Files with a header are not supported. A *FrameAlignmentError is returned if the input size is not
+// a whole number of frames. On error the output file may be incomplete.
+func ProcessFile(config ConverterConfig, inPath, outPath string) error {
+	frameSize := FrameSizeInBytes(config.FormatIn, config.ChannelsIn)
+	if frameSize == 0 {
+		return ErrInvalidArgs
+	}
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size()%int64(frameSize) != 0 {
+		return &FrameAlignmentError{Length: int(info.Size()), FrameSize: frameSize}
+	}
+
+	converter, err := InitConverter(config)
+	if err != nil {
+		return err
+	}
+	defer converter.Uninit()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	writer := &processFileWriter{
+		converter: converter,
+		out:       out,
+		frameSize: frameSize,
+		skip:      int64(converter.LatencyInFrames()),
+		remaining: info.Size() / int64(frameSize) * int64(config.SampleRateOut) / int64(config.SampleRateIn),
+	}
+
+	if mapped, unmap, err := mapFile(in, info.Size()); err == nil {
+		err = writer.convert(mapped)
+		if unmapErr := unmap(); err == nil {
+			err = unmapErr
+		}
+		if err != nil {
+			out.Close()
+			return err
+		}
+	} else {
+		chunk := make([]byte, processFileChunkFrames*frameSize)
+		for {
+			n, err := io.ReadFull(in, chunk)
+			if err == io.EOF {
+				break
+			}
+			if err != nil && err != io.ErrUnexpectedEOF {
+				out.Close()
+				return err
+			}
+			if err := writer.convert(chunk[:n]); err != nil {
+				out.Close()
+				return err
+			}
+		}
+	}
+
+	if err := writer.flush(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// processFileWriter converts the input of ProcessFile and writes the output. The first skip frames,
+// the delay of the resampler, are dropped, so output frame i lines up with the input at the same time.
+type processFileWriter struct {
+	converter *Converter
+	out       *os.File
+	frameSize int
+	skip      int64
+	remaining int64 // Number of output frames still to be written.
+}
+
+// convert converts whole frames, processFileChunkFrames at a time.
+func (w *processFileWriter) convert(frames []byte) error {
+	for len(frames) > 0 {
+		chunk := frames
+		if len(chunk) > processFileChunkFrames*w.frameSize {
+			chunk = chunk[:processFileChunkFrames*w.frameSize]
+		}
+		converted, consumed, err := w.converter.Process(chunk, len(chunk)/w.frameSize)
+		if err != nil {
+			return err
+		}
+		if err := w.write(converted); err != nil {
+			return err
+		}
+		if consumed == 0 && len(converted) == 0 {
+			break
+		}
+		frames = frames[consumed*w.frameSize:]
+	}
+	return nil
+}
+
+// flush feeds silence to the converter until the frames still held by the resampler are written.
+func (w *processFileWriter) flush() error {
+	silent := make([]byte, processFileChunkFrames*w.frameSize)
+	silence(w.converter.config.FormatIn, silent)
+	for w.remaining > 0 {
+		converted, _, err := w.converter.Process(silent, processFileChunkFrames)
+		if err != nil {
+			return err
+		}
+		if len(converted) == 0 {
+			break
+		}
+		if err := w.write(converted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes converted frames, dropping the delay of the resampler and frames past the expected
+// output length.
+func (w *processFileWriter) write(converted []byte) error {
+	outFrameSize := w.converter.outputFrameSize()
+	if w.skip > 0 {
+		skipped := int64(len(converted) / outFrameSize)
+		if skipped > w.skip {
+			skipped = w.skip
+		}
+		converted = converted[skipped*int64(outFrameSize):]
+		w.skip -= skipped
+	}
+	if frames := int64(len(converted) / outFrameSize); frames > w.remaining {
+		converted = converted[:w.remaining*int64(outFrameSize)]
+	}
+	w.remaining -= int64(len(converted) / outFrameSize)
+	_, err := w.out.Write(converted)
+	return err
+}
@@ -0,0 +1,120 @@
+package malgo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestRawPCM(t *testing.T) {
+	format := malgo.DataFormat{Format: malgo.FormatS24, Channels: 2, SampleRate: 48000}
+	path := filepath.Join(t.TempDir(), "frames.pcm")
+
+	frames := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	err := malgo.WriteRawPCM(path, frames, format)
+	assertNil(t, err, "No error expected writing raw PCM")
+
+	read, err := malgo.ReadRawPCM(path, format)
+	assertNil(t, err, "No error expected reading raw PCM")
+	assertTrue(t, bytes.Equal(frames, read), "Expected identical frames")
+
+	err = malgo.WriteRawPCM(path, frames[:5], format)
+	var alignmentErr *malgo.FrameAlignmentError
+	assertTrue(t, errors.As(err, &alignmentErr), "Expected an alignment error for a partial frame")
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected the alignment error to wrap ErrInvalidArgs")
+
+	err = os.WriteFile(path, frames[:7], 0644)
+	assertNil(t, err, "No error expected writing file")
+	_, err = malgo.ReadRawPCM(path, format)
+	assertTrue(t, errors.As(err, &alignmentErr), "Expected an alignment error for a truncated file")
+}
+
+func TestProcessFile(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.pcm")
+	outPath := filepath.Join(dir, "out.pcm")
+
+	// Enough frames for several chunks.
+	const frameCount = 10000
+	frames := malgo.GenerateChannelIdentification(malgo.FormatS16, 48000, 2, frameCount)
+	err := malgo.WriteRawPCM(inPath, frames, malgo.DataFormat{Format: malgo.FormatS16, Channels: 2, SampleRate: 48000})
+	assertNil(t, err, "No error expected writing raw PCM")
+
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatS16,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   1,
+		SampleRateIn:  48000,
+		SampleRateOut: 24000,
+	}
+	err = malgo.ProcessFile(config, inPath, outPath)
+	assertNil(t, err, "No error expected processing file")
+
+	out, err := malgo.ReadRawPCM(outPath, malgo.DataFormat{Format: malgo.FormatF32, Channels: 1, SampleRate: 24000})
+	assertNil(t, err, "No error expected reading output")
+	assertEqual(t, frameCount/2, len(out)/4, "Expected half the frames at half the rate")
+
+	// An impulse on the last input frame must survive the resampler delay.
+	format := malgo.DataFormat{Format: malgo.FormatF32, Channels: 1, SampleRate: 48000}
+	impulse := make([]byte, frameCount*4)
+	binary.LittleEndian.PutUint32(impulse[(frameCount-1)*4:], math.Float32bits(1))
+	err = malgo.WriteRawPCM(inPath, impulse, format)
+	assertNil(t, err, "No error expected writing raw PCM")
+	upsample := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    1,
+		ChannelsOut:   1,
+		SampleRateIn:  48000,
+		SampleRateOut: 96000,
+	}
+	err = malgo.ProcessFile(upsample, inPath, outPath)
+	assertNil(t, err, "No error expected processing file")
+	out, err = malgo.ReadRawPCM(outPath, format)
+	assertNil(t, err, "No error expected reading output")
+	assertEqual(t, frameCount*2, len(out)/4, "Expected twice the frames at twice the rate")
+	peak := math.Float32frombits(binary.LittleEndian.Uint32(out[(frameCount*2-2)*4:]))
+	assertTrue(t, peak > 0.5, "Expected the impulse at the end of the output")
+
+	// The tail past the last whole chunk is too short for an output frame of its own at a third of the
+	// rate. It must still be converted, so the output matches converting the input in one call.
+	const tailFrameCount = 4096 + 5
+	sine := make([]byte, tailFrameCount*4)
+	for i := 0; i < tailFrameCount; i++ {
+		binary.LittleEndian.PutUint32(sine[i*4:], math.Float32bits(float32(math.Sin(float64(i)*0.01))))
+	}
+	err = malgo.WriteRawPCM(inPath, sine, format)
+	assertNil(t, err, "No error expected writing raw PCM")
+	downsample := upsample
+	downsample.SampleRateOut = 16000
+	downsample.Resampling.Linear.LpfOrder = 4
+	err = malgo.ProcessFile(downsample, inPath, outPath)
+	assertNil(t, err, "No error expected processing file")
+	out, err = malgo.ReadRawPCM(outPath, format)
+	assertNil(t, err, "No error expected reading output")
+	assertEqual(t, tailFrameCount/3, len(out)/4, "Expected a third of the frames at a third of the rate")
+
+	converter, err := malgo.InitConverter(downsample)
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+	padded := append(sine, make([]byte, 4096*4)...)
+	reference := make([]byte, tailFrameCount*4)
+	_, written, err := converter.ProcessFrames(padded, len(padded)/4, reference, tailFrameCount)
+	assertNil(t, err, "No error expected converting in one call")
+	latency := converter.LatencyInFrames()
+	assertTrue(t, written-latency >= len(out)/4, "Expected enough reference frames")
+	assertTrue(t, bytes.Equal(reference[latency*4:latency*4+len(out)], out), "Expected the output of a single conversion")
+
+	err = os.WriteFile(inPath, frames[:7], 0644)
+	assertNil(t, err, "No error expected writing file")
+	err = malgo.ProcessFile(config, inPath, outPath)
+	var alignmentErr *malgo.FrameAlignmentError
+	assertTrue(t, errors.As(err, &alignmentErr), "Expected an alignment error for a truncated file")
+}
@@ -0,0 +1,159 @@
+package malgo
+
+// highPrecisionTaps is the number of input frames on each side of an output position used by the
+// float64 resampler of HighPrecision converters.
+const highPrecisionTaps = 32
+
+// highPrecisionMaxPhases bounds the size of the coefficient table. Rate pairs with more fractional
+// positions, which only happens for unusual rates, compute the coefficients of every output frame.
+const highPrecisionMaxPhases = 1024
+
+// highPrecisionRolloff places the cutoff of the anti-aliasing filter below the Nyquist frequency of
+// the lower rate, leaving room for the transition band of the window.
+const highPrecisionRolloff = 0.9
+
+// highPrecisionResampler is the resample stage of a HighPrecision converter. It converts the input
+// samples to float64, interpolates them with a Blackman windowed sinc filter and rounds the result to
+// the output format only once, so no precision is lost to 32-bit float intermediates.
+//
+// The output is time aligned with the input: output frame k is interpolated at input position
+// k*rateIn/rateOut. Input is held back until the frames after that position are available.
+type highPrecisionResampler struct {
+	formatIn, formatOut FormatType
+	channels            int
+	rateIn, rateOut     int64 // Reduced by their greatest common divisor.
+	cutoff              float64
+	phases              [][2 * highPrecisionTaps]float64
+	scratch             [2 * highPrecisionTaps]float64
+
+	// buffer holds interleaved input frames; the first highPrecisionTaps of the stream are silence so
+	// that the first output frames have a full filter history.
+	buffer []float64
+	// position is the input position of the next output frame in the buffer, in 1/rateOut frames.
+	position int64
+}
+
+func newHighPrecisionResampler(config ConverterConfig) *highPrecisionResampler {
+	divisor := gcd(config.SampleRateIn, config.SampleRateOut)
+	r := &highPrecisionResampler{
+		formatIn:  config.FormatIn,
+		formatOut: config.FormatOut,
+		channels:  config.ChannelsIn,
+		rateIn:    int64(config.SampleRateIn / divisor),
+		rateOut:   int64(config.SampleRateOut / divisor),
+		cutoff:    highPrecisionRolloff,
+	}
+	if r.rateOut < r.rateIn {
+		r.cutoff *= float64(r.rateOut) / float64(r.rateIn)
+	}
+	if r.rateOut <= highPrecisionMaxPhases {
+		r.phases = make([][2 * highPrecisionTaps]float64, r.rateOut)
+		for phase := range r.phases {
+			r.coefficients(int64(phase), &r.phases[phase])
+		}
+	}
+	r.buffer = make([]float64, highPrecisionTaps*r.channels)
+	r.position = highPrecisionTaps * r.rateOut
+	return r
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// coefficients computes the filter for an output position phase/rateOut frames past an input frame.
+// They are normalized to a sum of one, so the DC gain is exact at every position.
+func (r *highPrecisionResampler) coefficients(phase int64, coefficients *[2 * highPrecisionTaps]float64) {
+	offset := float64(phase) / float64(r.rateOut)
+	sum := 0.0
+	for j := range coefficients {
+		x := offset - float64(j-highPrecisionTaps+1)
+		coefficients[j] = sinc(r.cutoff*x) * blackman(x/highPrecisionTaps)
+		sum += coefficients[j]
+	}
+	for j := range coefficients {
+		coefficients[j] /= sum
+	}
+}
+
+// bufferedFrames returns the number of input frames held in the buffer.
+func (r *highPrecisionResampler) bufferedFrames() int64 {
+	return int64(len(r.buffer) / r.channels)
+}
+
+// frameAvailable reports whether the buffer holds every input frame the filter needs at position.
+func (r *highPrecisionResampler) frameAvailable(position, bufferedFrames int64) bool {
+	return position/r.rateOut+highPrecisionTaps < bufferedFrames
+}
+
+// process has the semantics of Converter.ProcessFrames. It stops consuming input once the output is full.
+func (r *highPrecisionResampler) process(pFramesIn []byte, frameCountIn int, pFramesOut []byte, frameCountOut int) (int, int) {
+	read, written := 0, 0
+	for written < frameCountOut {
+		if !r.frameAvailable(r.position, r.bufferedFrames()) {
+			if read == frameCountIn {
+				break
+			}
+			for c := 0; c < r.channels; c++ {
+				v := 0.0
+				if len(pFramesIn) > 0 {
+					v = sampleAt(r.formatIn, pFramesIn, read*r.channels+c)
+				}
+				r.buffer = append(r.buffer, v)
+			}
+			read++
+			continue
+		}
+
+		if len(pFramesOut) > 0 {
+			coefficients := &r.scratch
+			if r.phases != nil {
+				coefficients = &r.phases[r.position%r.rateOut]
+			} else {
+				r.coefficients(r.position%r.rateOut, coefficients)
+			}
+			first := int(r.position/r.rateOut) - highPrecisionTaps + 1
+			for c := 0; c < r.channels; c++ {
+				v := 0.0
+				for j, coefficient := range coefficients {
+					v += coefficient * r.buffer[(first+j)*r.channels+c]
+				}
+				setSampleAt(r.formatOut, pFramesOut, written*r.channels+c, v)
+			}
+		}
+		written++
+		r.position += r.rateIn
+	}
+
+	// Drop the frames no output position needs anymore once there are enough of them to be worth a copy.
+	if unused := int(r.position/r.rateOut) - highPrecisionTaps + 1; unused > 4096 {
+		r.buffer = append(r.buffer[:0], r.buffer[unused*r.channels:]...)
+		r.position -= int64(unused) * r.rateOut
+	}
+	return read, written
+}
+
+// expectOutputFrameCount returns the number of frames process writes for inputFrameCount input frames.
+func (r *highPrecisionResampler) expectOutputFrameCount(inputFrameCount int) int {
+	available := (r.bufferedFrames() + int64(inputFrameCount) - highPrecisionTaps) * r.rateOut
+	if available <= r.position {
+		return 0
+	}
+	return int((available - r.position + r.rateIn - 1) / r.rateIn)
+}
+
+// requiredInputFrameCount returns the number of input frames process needs to write outputFrameCount frames.
+func (r *highPrecisionResampler) requiredInputFrameCount(outputFrameCount int) int {
+	if outputFrameCount == 0 {
+		return 0
+	}
+	last := r.position + int64(outputFrameCount-1)*r.rateIn
+	required := last/r.rateOut + highPrecisionTaps + 1 - r.bufferedFrames()
+	if required < 0 {
+		return 0
+	}
+	return int(required)
+}
@@ -0,0 +1,154 @@
+package malgo_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+// sineS32 returns frameCount frames of a mono FormatS32 sine with the given amplitude.
+func sineS32(frameCount int, frequency, sampleRate, amplitude float64) []byte {
+	frames := make([]byte, frameCount*4)
+	for i := 0; i < frameCount; i++ {
+		v := int32(math.Round(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/sampleRate) * math.MaxInt32))
+		frames[i*4] = byte(v)
+		frames[i*4+1] = byte(v >> 8)
+		frames[i*4+2] = byte(v >> 16)
+		frames[i*4+3] = byte(v >> 24)
+	}
+	return frames
+}
+
+// sineSNR fits a sine of the given frequency to mono FormatS32 frames and returns the signal to residual
+// ratio in dB, leaving out the first and last 10% of the frames. The fit spans whole periods, so that
+// the sin and cos projections are exact.
+func sineSNR(frames []byte, frequency, sampleRate float64) float64 {
+	frameCount := len(frames) / 4
+	period := int(sampleRate / frequency)
+	start := frameCount / 10
+	end := start + (frameCount-2*start)/period*period
+	omega := 2 * math.Pi * frequency / sampleRate
+	sample := func(i int) float64 {
+		return float64(int32(uint32(frames[i*4])|uint32(frames[i*4+1])<<8|uint32(frames[i*4+2])<<16|uint32(frames[i*4+3])<<24)) / math.MaxInt32
+	}
+
+	var sinSum, cosSum float64
+	for i := start; i < end; i++ {
+		sinSum += sample(i) * math.Sin(omega*float64(i))
+		cosSum += sample(i) * math.Cos(omega*float64(i))
+	}
+	amplitude := 2 * math.Hypot(sinSum, cosSum) / float64(end-start)
+	phase := math.Atan2(cosSum, sinSum)
+
+	var signal, noise float64
+	for i := start; i < end; i++ {
+		reference := amplitude * math.Sin(omega*float64(i)+phase)
+		signal += reference * reference
+		noise += (sample(i) - reference) * (sample(i) - reference)
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+// resampleS32 converts mono FormatS32 frames from 44.1 to 48 kHz in one call.
+func resampleS32(t *testing.T, highPrecision bool, frames []byte) []byte {
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn: malgo.FormatS32, FormatOut: malgo.FormatS32,
+		ChannelsIn: 1, ChannelsOut: 1,
+		SampleRateIn: 44100, SampleRateOut: 48000,
+		HighPrecision: highPrecision,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+
+	frameCount := len(frames) / 4
+	expected, err := converter.ExpectOutputFrameCount(frameCount)
+	assertNil(t, err, "No error expected computing the output frame count")
+	out := make([]byte, expected*4)
+	read, written, err := converter.ProcessFrames(frames, frameCount, out, expected)
+	assertNil(t, err, "No error expected converting")
+	assertEqual(t, frameCount, read, "Expected every input frame to be consumed")
+	assertEqual(t, expected, written, "Expected the announced number of output frames")
+	return out[:written*4]
+}
+
+func TestConverterHighPrecisionNoiseFloor(t *testing.T) {
+	frames := sineS32(44100, 1000, 44100, 0.5)
+
+	defaultSNR := sineSNR(resampleS32(t, false, frames), 1000, 48000)
+	highPrecisionSNR := sineSNR(resampleS32(t, true, frames), 1000, 48000)
+	t.Logf("SNR default %.1f dB, high precision %.1f dB", defaultSNR, highPrecisionSNR)
+	assertTrue(t, highPrecisionSNR > 110, "Expected a noise floor below -110 dB")
+	assertTrue(t, highPrecisionSNR > defaultSNR+40, "Expected a noise floor at least 40 dB lower than the default")
+}
+
+func TestConverterHighPrecisionStreaming(t *testing.T) {
+	frames := sineS32(4410, 1000, 44100, 0.5)
+	whole := resampleS32(t, true, frames)
+
+	converter, err := malgo.InitConverter(malgo.ConverterConfig{
+		FormatIn: malgo.FormatS32, FormatOut: malgo.FormatS32,
+		ChannelsIn: 1, ChannelsOut: 1,
+		SampleRateIn: 44100, SampleRateOut: 48000,
+		HighPrecision: true,
+	})
+	assertNil(t, err, "No error expected initializing converter")
+	defer converter.Uninit()
+	assertEqual(t, 0, converter.LatencyInFrames(), "Expected no latency")
+
+	var streamed []byte
+	for start := 0; start < len(frames)/4; start += 100 {
+		chunk := frames[start*4:]
+		if len(chunk) > 400 {
+			chunk = chunk[:400]
+		}
+		expected, err := converter.ExpectOutputFrameCount(len(chunk) / 4)
+		assertNil(t, err, "No error expected computing the output frame count")
+		out := make([]byte, expected*4)
+		read, written, err := converter.ProcessFrames(chunk, len(chunk)/4, out, expected)
+		assertNil(t, err, "No error expected converting")
+		assertEqual(t, len(chunk)/4, read, "Expected the whole chunk to be consumed")
+		streamed = append(streamed, out[:written*4]...)
+	}
+	assertTrue(t, bytes.Equal(whole, streamed), "Expected the same output in chunks as in one call")
+
+	// The input RequiredInputFrameCount asks for produces exactly the requested frames.
+	required, err := converter.RequiredInputFrameCount(480)
+	assertNil(t, err, "No error expected computing the required input")
+	out := make([]byte, 480*4)
+	read, written, err := converter.ProcessFrames(nil, required, out, 480)
+	assertNil(t, err, "No error expected converting silence")
+	assertEqual(t, required, read, "Expected the required input to be consumed")
+	assertEqual(t, 480, written, "Expected the requested output frames")
+}
+
+func TestConverterHighPrecisionConfig(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn: malgo.FormatS32, FormatOut: malgo.FormatS16,
+		ChannelsIn: 2, ChannelsOut: 2,
+		SampleRateIn: 96000, SampleRateOut: 44100,
+		HighPrecision: true,
+	}
+	converter, err := malgo.InitConverter(config)
+	assertNil(t, err, "No error expected initializing converter")
+	ratio := converter.RateRatio()
+	err = converter.SetRate(48000, 44100)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error changing the rates of a HighPrecision converter")
+	err = converter.SetRateRatio(0.5)
+	assertEqual(t, malgo.ErrInvalidOperation, err, "Expected error changing the ratio of a HighPrecision converter")
+	assertEqual(t, ratio, converter.RateRatio(), "Expected the rate ratio to be unchanged")
+	converter.Uninit()
+
+	for _, modify := range []func(*malgo.ConverterConfig){
+		func(c *malgo.ConverterConfig) { c.ChannelsOut = 1 },
+		func(c *malgo.ConverterConfig) { c.DitherMode = malgo.DitherModeTriangle },
+		func(c *malgo.ConverterConfig) { c.AllowDynamicSampleRate = true },
+	} {
+		invalid := config
+		modify(&invalid)
+		_, err = malgo.InitConverter(invalid)
+		assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected an error for a configuration HighPrecision does not support")
+	}
+}
@@ -0,0 +1,91 @@
+package malgo
+
+import (
+	"math"
+)
+
+// MeasureResampleSNR measures the quality of a resampler configuration, in dB.
+//
+// One second of a full scale sine at 40% of the lower sample rate, close to its Nyquist frequency,
+// is resampled from fromRate to toRate and back. The result is compared with the sine of the same
+// frequency that best fits the round trip output, so latency and the level lost in the low-pass filter
+// do not count as error, while aliasing, imaging and noise do. The first and last 10% of the output
+// are skipped to leave out the filter transients.
+func MeasureResampleSNR(algorithm ResampleAlgorithm, lpfOrder int, fromRate, toRate int) (float64, error) {
+	if fromRate <= 0 || toRate <= 0 || lpfOrder < 0 {
+		return 0, ErrInvalidArgs
+	}
+
+	frequency := 0.4 * math.Min(float64(fromRate), float64(toRate))
+	omega := 2 * math.Pi * frequency / float64(fromRate)
+	frames := make([]byte, fromRate*4)
+	for i := 0; i < fromRate; i++ {
+		setSampleAt(FormatF32, frames, i, math.Sin(omega*float64(i)))
+	}
+
+	config := ConverterConfig{
+		FormatIn:   FormatF32,
+		FormatOut:  FormatF32,
+		ChannelsIn: 1, ChannelsOut: 1,
+		Resampling: ResampleConfig{Algorithm: algorithm, Linear: ResampleLinearConfig{LpfOrder: uint32(lpfOrder)}},
+	}
+	for _, rates := range [][2]int{{fromRate, toRate}, {toRate, fromRate}} {
+		config.SampleRateIn, config.SampleRateOut = rates[0], rates[1]
+		converted, err := resampleAll(config, frames)
+		if err != nil {
+			return 0, err
+		}
+		frames = converted
+	}
+
+	frameCount := len(frames) / 4
+	start, end := frameCount/10, frameCount-frameCount/10
+	if end-start < 2 {
+		return 0, ErrInvalidArgs
+	}
+
+	// Least squares fit of the output against sin and cos of the test frequency.
+	var sinSum, cosSum float64
+	for i := start; i < end; i++ {
+		y := sampleAt(FormatF32, frames, i)
+		sinSum += y * math.Sin(omega*float64(i))
+		cosSum += y * math.Cos(omega*float64(i))
+	}
+	amplitude := 2 * math.Hypot(sinSum, cosSum) / float64(end-start)
+	phase := math.Atan2(cosSum, sinSum)
+
+	var signal, noise float64
+	for i := start; i < end; i++ {
+		reference := amplitude * math.Sin(omega*float64(i)+phase)
+		e := sampleAt(FormatF32, frames, i) - reference
+		signal += reference * reference
+		noise += e * e
+	}
+	if noise == 0 {
+		return math.Inf(1), nil
+	}
+	return 10 * math.Log10(signal/noise), nil
+}
+
+// resampleAll converts a whole buffer of frames with a temporary converter.
+func resampleAll(config ConverterConfig, frames []byte) ([]byte, error) {
+	converter, err := InitConverter(config)
+	if err != nil {
+		return nil, err
+	}
+	defer converter.Uninit()
+
+	frameSize := FrameSizeInBytes(config.FormatIn, config.ChannelsIn)
+	frameCount := len(frames) / frameSize
+	outputFrameCount, err := converter.ExpectOutputFrameCount(frameCount)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, outputFrameCount*FrameSizeInBytes(config.FormatOut, config.ChannelsOut))
+	_, written, err := converter.ProcessFrames(frames, frameCount, out, outputFrameCount)
+	if err != nil {
+		return nil, err
+	}
+	return out[:written*FrameSizeInBytes(config.FormatOut, config.ChannelsOut)], nil
+}
@@ -0,0 +1,24 @@
+package malgo_test
+
+import (
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestMeasureResampleSNR(t *testing.T) {
+	passthrough, err := malgo.MeasureResampleSNR(malgo.ResampleAlgorithmLinear, 4, 48000, 48000)
+	assertNil(t, err, "No error expected measuring without resampling")
+	assertTrue(t, passthrough > 100, "Expected a lossless round trip without resampling")
+
+	unfiltered, err := malgo.MeasureResampleSNR(malgo.ResampleAlgorithmLinear, 0, 48000, 16000)
+	assertNil(t, err, "No error expected measuring without low-pass filter")
+	filtered, err := malgo.MeasureResampleSNR(malgo.ResampleAlgorithmLinear, 8, 48000, 16000)
+	assertNil(t, err, "No error expected measuring with low-pass filter")
+	if filtered < unfiltered+6 {
+		t.Fatalf("expected the low-pass filter to reduce aliasing: %.2f dB without, %.2f dB with", unfiltered, filtered)
+	}
+
+	_, err = malgo.MeasureResampleSNR(malgo.ResampleAlgorithmLinear, 4, 0, 48000)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error for an invalid sample rate")
+}
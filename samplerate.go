@@ -0,0 +1,44 @@
+package malgo
+
+// #include "malgo.h"
+import "C"
+
+// Range of sample rates supported by miniaudio's devices.
+const (
+	MinSampleRate = C.ma_standard_sample_rate_min
+	MaxSampleRate = C.ma_standard_sample_rate_max
+)
+
+// standardSampleRates are the rates NearestStandardSampleRate snaps to, in ascending order.
+var standardSampleRates = []int{8000, 11025, 16000, 22050, 44100, 48000, 88200, 96000, 192000}
+
+// ValidateSampleRate returns an error wrapping ErrInvalidArgs if rate is not positive or lies
+// outside [MinSampleRate, MaxSampleRate]. Use it on rates read from untrusted sources, such as file headers.
+func ValidateSampleRate(rate int) error {
+	if rate <= 0 {
+		return invalidConfig("sample rate %d is not positive", rate)
+	}
+	if rate < MinSampleRate || rate > MaxSampleRate {
+		return invalidConfig("sample rate %d is outside [%d, %d]", rate, MinSampleRate, MaxSampleRate)
+	}
+	return nil
+}
+
+// NearestStandardSampleRate returns the standard rate closest to rate, out of 8000, 11025, 16000,
+// 22050, 44100, 48000, 88200, 96000 and 192000 Hz. A rate halfway between two is snapped down.
+func NearestStandardSampleRate(rate int) int {
+	nearest := standardSampleRates[0]
+	for _, standard := range standardSampleRates[1:] {
+		if absInt(standard-rate) < absInt(nearest-rate) {
+			nearest = standard
+		}
+	}
+	return nearest
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
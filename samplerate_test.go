@@ -0,0 +1,51 @@
+package malgo_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestValidateSampleRate(t *testing.T) {
+	for _, rate := range []int{8000, 44100, 48000, 384000} {
+		assertNil(t, malgo.ValidateSampleRate(rate), "No error expected for a supported rate")
+	}
+	for _, rate := range []int{-44100, 0, 7999, 384001, 1 << 30} {
+		err := malgo.ValidateSampleRate(rate)
+		assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for an unsupported rate")
+	}
+}
+
+func TestNearestStandardSampleRate(t *testing.T) {
+	tests := []struct {
+		rate, expected int
+	}{
+		{0, 8000},
+		{-1, 8000},
+		{11000, 11025},
+		{44099, 44100},
+		{46050, 44100},
+		{47000, 48000},
+		{96001, 96000},
+		{1000000, 192000},
+	}
+	for _, test := range tests {
+		assertEqual(t, test.expected, malgo.NearestStandardSampleRate(test.rate), "Unexpected nearest rate")
+	}
+}
+
+func TestConverterZeroSampleRate(t *testing.T) {
+	config := malgo.ConverterConfig{
+		FormatIn:      malgo.FormatF32,
+		FormatOut:     malgo.FormatF32,
+		ChannelsIn:    2,
+		ChannelsOut:   2,
+		SampleRateIn:  0,
+		SampleRateOut: 48000,
+	}
+	_, err := malgo.InitConverter(config)
+	assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected error for a zero sample rate")
+	assertTrue(t, strings.Contains(err.Error(), "SampleRateIn 0"), "Expected the error to name the rate")
+}
@@ -0,0 +1,120 @@
+package malgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// bytesAsFloat32 reinterprets a buffer of FormatF32 samples without copying.
+func bytesAsFloat32(b []byte) []float32 {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*float32)(unsafe.Pointer(&b[0])), len(b)/4)
+}
+
+// bytesAsInt16 reinterprets a buffer of FormatS16 samples without copying.
+func bytesAsInt16(b []byte) []int16 {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*int16)(unsafe.Pointer(&b[0])), len(b)/2)
+}
+
+// sampleAt reads the sample at the given sample index and returns it normalized to [-1, 1].
+func sampleAt(format FormatType, b []byte, index int) float64 {
+	switch format {
+	case FormatU8:
+		return (float64(b[index]) - 128) / 128
+	case FormatS16:
+		return float64(int16(binary.LittleEndian.Uint16(b[index*2:]))) / 32768
+	case FormatS24:
+		return float64(ReadSampleS24(b, index)) / 8388608
+	case FormatS32:
+		return float64(int32(binary.LittleEndian.Uint32(b[index*4:]))) / 2147483648
+	case FormatF32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b[index*4:])))
+	}
+	return 0
+}
+
+// setSampleAt writes a normalized sample at the given sample index, saturating integer formats.
+func setSampleAt(format FormatType, b []byte, index int, v float64) {
+	switch format {
+	case FormatU8:
+		b[index] = uint8(clamp(math.Round(v*128)+128, 0, math.MaxUint8))
+	case FormatS16:
+		binary.LittleEndian.PutUint16(b[index*2:], uint16(int16(clamp(math.Round(v*32768), math.MinInt16, math.MaxInt16))))
+	case FormatS24:
+		WriteSampleS24(b, index, int32(clamp(math.Round(v*8388608), -8388608, 8388607)))
+	case FormatS32:
+		binary.LittleEndian.PutUint32(b[index*4:], uint32(int32(clamp(math.Round(v*2147483648), math.MinInt32, math.MaxInt32))))
+	case FormatF32:
+		binary.LittleEndian.PutUint32(b[index*4:], math.Float32bits(float32(v)))
+	}
+}
+
+// ReadSampleS24 reads the FormatS24 sample at the given sample index, stored as 3 little-endian bytes,
+// and returns it sign extended, in the range [-8388608, 8388607].
+func ReadSampleS24(b []byte, sampleIndex int) int32 {
+	p := b[sampleIndex*3 : sampleIndex*3+3]
+	return int32(uint32(p[0])<<8|uint32(p[1])<<16|uint32(p[2])<<24) >> 8
+}
+
+// WriteSampleS24 writes value as the FormatS24 sample at the given sample index. Values outside
+// [-8388608, 8388607] are saturated.
+func WriteSampleS24(b []byte, sampleIndex int, value int32) {
+	if value < -8388608 {
+		value = -8388608
+	} else if value > 8388607 {
+		value = 8388607
+	}
+	p := b[sampleIndex*3 : sampleIndex*3+3]
+	p[0] = byte(value)
+	p[1] = byte(value >> 8)
+	p[2] = byte(value >> 16)
+}
+
+// FrameAlignmentError reports a buffer whose length is not a whole number of frames. This is easy to
+// miss with FormatS24, whose frames are 9 bytes for 3 channels and 15 bytes for 5, so sizes computed
+// for another format or channel count leave a trailing partial frame. It unwraps to ErrInvalidArgs.
+type FrameAlignmentError struct {
+	Length    int // Length of the buffer in bytes.
+	FrameSize int // Size of a frame in bytes.
+}
+
+func (e *FrameAlignmentError) Error() string {
+	return fmt.Sprintf("malgo: buffer of %d bytes is not a whole number of %d-byte frames", e.Length, e.FrameSize)
+}
+
+// Unwrap returns ErrInvalidArgs.
+func (e *FrameAlignmentError) Unwrap() error {
+	return ErrInvalidArgs
+}
+
+// WholeFrameCount returns the number of frames of the format and channel count held by b. A trailing
+// partial frame is reported as a *FrameAlignmentError instead of being dropped. ErrInvalidArgs is
+// returned for an unknown format or a channel count below one.
+func WholeFrameCount(format FormatType, channels int, b []byte) (int, error) {
+	frameSize := FrameSizeInBytes(format, channels)
+	if frameSize == 0 {
+		return 0, ErrInvalidArgs
+	}
+	if len(b)%frameSize != 0 {
+		return 0, &FrameAlignmentError{Length: len(b), FrameSize: frameSize}
+	}
+	return len(b) / frameSize, nil
+}
+
+// clamp limits v to the range [lo, hi].
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package malgo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestSampleS24(t *testing.T) {
+	tests := []struct {
+		bytes [3]byte
+		value int32
+	}{
+		{[3]byte{0x00, 0x00, 0x00}, 0},
+		{[3]byte{0x01, 0x00, 0x00}, 1},
+		{[3]byte{0xff, 0xff, 0xff}, -1},
+		{[3]byte{0xff, 0xff, 0x7f}, 8388607},
+		{[3]byte{0x00, 0x00, 0x80}, -8388608},
+		{[3]byte{0x56, 0x34, 0x12}, 0x123456},
+	}
+
+	b := make([]byte, 2*3)
+	for _, test := range tests {
+		copy(b[3:], test.bytes[:])
+		assertEqual(t, test.value, malgo.ReadSampleS24(b, 1), "Unexpected sample value")
+
+		malgo.WriteSampleS24(b, 0, test.value)
+		assertEqual(t, string(test.bytes[:]), string(b[:3]), "Unexpected sample bytes")
+	}
+
+	malgo.WriteSampleS24(b, 0, 1<<30)
+	assertEqual(t, int32(8388607), malgo.ReadSampleS24(b, 0), "Expected large values to saturate")
+	malgo.WriteSampleS24(b, 0, -1<<30)
+	assertEqual(t, int32(-8388608), malgo.ReadSampleS24(b, 0), "Expected small values to saturate")
+}
+
+func TestWholeFrameCount(t *testing.T) {
+	for _, channels := range []int{3, 5} {
+		frameSize := malgo.FrameSizeInBytes(malgo.FormatS24, channels)
+		assertEqual(t, 3*channels, frameSize, "Expected 3 bytes per S24 sample")
+
+		frameCount, err := malgo.WholeFrameCount(malgo.FormatS24, channels, make([]byte, 4*frameSize))
+		assertNil(t, err, "No error expected for whole frames")
+		assertEqual(t, 4, frameCount, "Unexpected frame count")
+
+		// A buffer sized as if samples were 4 bytes.
+		_, err = malgo.WholeFrameCount(malgo.FormatS24, channels, make([]byte, 4*channels))
+		var alignmentErr *malgo.FrameAlignmentError
+		assertTrue(t, errors.As(err, &alignmentErr), "Expected an alignment error for a partial frame")
+		assertEqual(t, frameSize, alignmentErr.FrameSize, "Unexpected frame size in error")
+		assertTrue(t, errors.Is(err, malgo.ErrInvalidArgs), "Expected the alignment error to wrap ErrInvalidArgs")
+	}
+
+	_, err := malgo.WholeFrameCount(malgo.FormatS24, 0, nil)
+	assertEqual(t, malgo.ErrInvalidArgs, err, "Expected error without channels")
+	assertEqual(t, 0, malgo.FrameSizeInBytes(malgo.FormatS24, -3), "Expected no frame size for a negative channel count")
+}
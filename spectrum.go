@@ -0,0 +1,97 @@
+package malgo
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// WindowType type.
+type WindowType uint32
+
+// WindowType enumeration.
+const (
+	WindowHann WindowType = iota
+	WindowHamming
+	WindowBlackman
+)
+
+// Spectrum returns the FFT of every channel of interleaved frames, indexed by channel and then bin.
+//
+// Each channel is converted to float, multiplied by the window and zero padded to the next power of
+// two, N, before running a radix-2 FFT. Bin k is centered on k*sampleRate/N Hz; bins above N/2 mirror
+// the ones below as the input is real. Nil is returned for invalid arguments.
+func Spectrum(format FormatType, frames []byte, frameCount, channels int, window WindowType) [][]complex128 {
+	sampleSize := SampleSizeInBytes(format)
+	if sampleSize == 0 || channels <= 0 || frameCount <= 0 || len(frames) < frameCount*channels*sampleSize ||
+		window > WindowBlackman {
+		return nil
+	}
+
+	size := 1
+	for size < frameCount {
+		size <<= 1
+	}
+
+	coefficients := windowCoefficients(window, frameCount)
+	spectrum := make([][]complex128, channels)
+	for channel := range spectrum {
+		bins := make([]complex128, size)
+		for frame := 0; frame < frameCount; frame++ {
+			bins[frame] = complex(sampleAt(format, frames, frame*channels+channel)*coefficients[frame], 0)
+		}
+		fft(bins)
+		spectrum[channel] = bins
+	}
+
+	return spectrum
+}
+
+// windowCoefficients returns the symmetric window of the given length.
+func windowCoefficients(window WindowType, length int) []float64 {
+	coefficients := make([]float64, length)
+	if length == 1 {
+		coefficients[0] = 1
+		return coefficients
+	}
+
+	for i := range coefficients {
+		x := 2 * math.Pi * float64(i) / float64(length-1)
+		switch window {
+		case WindowHann:
+			coefficients[i] = 0.5 - 0.5*math.Cos(x)
+		case WindowHamming:
+			coefficients[i] = 0.54 - 0.46*math.Cos(x)
+		case WindowBlackman:
+			coefficients[i] = 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+		}
+	}
+	return coefficients
+}
+
+// fft transforms x in place. The length of x must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for length := 2; length <= n; length <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(length)))
+		for start := 0; start < n; start += length {
+			w := complex(1, 0)
+			for k := 0; k < length/2; k++ {
+				even, odd := x[start+k], x[start+k+length/2]*w
+				x[start+k] = even + odd
+				x[start+k+length/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package malgo_test
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestSpectrum(t *testing.T) {
+	const sampleRate = 48000
+
+	// 1500 Hz falls exactly on bin 32 of a 1024 point FFT at 48 kHz. The frame count is padded up to 1024.
+	frames := sineF32(1500, 1, sampleRate, 1000, 2)
+	spectrum := malgo.Spectrum(malgo.FormatF32, frames, 1000, 2, malgo.WindowHann)
+	assertEqual(t, 2, len(spectrum), "Expected one spectrum per channel")
+	assertEqual(t, 1024, len(spectrum[0]), "Expected bins padded to a power of two")
+
+	for _, bins := range spectrum {
+		peak := 0
+		for k := 1; k < len(bins)/2; k++ {
+			if cmplx.Abs(bins[k]) > cmplx.Abs(bins[peak]) {
+				peak = k
+			}
+		}
+		assertEqual(t, 32, peak, "Expected the peak at the tone's bin")
+		assertTrue(t, cmplx.Abs(bins[200]) < cmplx.Abs(bins[peak])/1000, "Expected the window to suppress leakage")
+	}
+
+	assertTrue(t, malgo.Spectrum(malgo.FormatF32, frames, 1001, 2, malgo.WindowHann) == nil, "Expected nil for a short buffer")
+}